@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/eallender/nats-ls/internal/logger"
+	"github.com/eallender/nats-ls/internal/monitor"
+)
+
+// runList runs discovery without the TUI for the given duration and prints the subject tree,
+// failing if any expected subject or wildcard wasn't seen
+func runList(duration time.Duration, asJSON bool, asDOT bool, expected []string) error {
+	if !cfg.NatsDiscoveryEnabled {
+		return fmt.Errorf("--list requires discovery, which is disabled")
+	}
+
+	nc, err := monitor.Connect(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS at %s: %w", cfg.NatsAddress, err)
+	}
+	defer monitor.Drain(nc, cfg.NatsDrainTimeout())
+
+	discovery := monitor.NewDiscovery(nc, cfg.NatsDiscoveryRateWindowSeconds, cfg.NatsDiscoveryHistorySeconds, cfg.NatsDiscoveryMaxSubjects, cfg.NatsDiscoveryPreviewBytes, cfg.NatsDiscoveryStaleTTL(), cfg.NatsDiscoveryIgnorePrefixes)
+
+	// Stop early on Ctrl+C, still printing what was discovered so far
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := discovery.Start(ctx, cfg.NatsDiscoveryPendingLimit, cfg.NatsDiscoveryStorageLimitMB, cfg.NatsDiscoveryQueueGroup); err != nil {
+		return fmt.Errorf("failed to start discovery: %w", err)
+	}
+	logger.Log.Info("Running headless discovery", "address", cfg.NatsAddress, "duration", duration)
+
+	select {
+	case <-time.After(duration):
+	case <-ctx.Done():
+	}
+
+	snapshot := discovery.Snapshot()
+	discovery.Stop()
+
+	if err := printSubjects(monitor.BuildSubjectTree(snapshot, cfg.NatsSubjectSeparator), asJSON, asDOT); err != nil {
+		return err
+	}
+	return checkExpected(expected, snapshot, cfg.NatsSubjectSeparator)
+}
+
+// printSubjects writes the subject tree to stdout as text, JSON or a DOT graph
+func printSubjects(tree *monitor.SubjectTree, asJSON bool, asDOT bool) error {
+	if asJSON {
+		nodes := tree.Children
+		if nodes == nil {
+			nodes = []*monitor.SubjectTree{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(nodes)
+	}
+	if asDOT {
+		return tree.WriteDOT(os.Stdout)
+	}
+	if len(tree.Children) == 0 {
+		fmt.Fprintln(os.Stderr, "No subjects discovered")
+		return nil
+	}
+	printTree(os.Stdout, tree.Children, 0)
+	return nil
+}
+
+// checkExpected returns an error listing the expected subjects or wildcards, split on sep,
+// that no discovered subject matched
+func checkExpected(expected []string, subjects []monitor.SubjectSnapshot, sep string) error {
+	var missing []string
+	for _, pattern := range expected {
+		seen := slices.ContainsFunc(subjects, func(subject monitor.SubjectSnapshot) bool {
+			return monitor.MatchSubject(pattern, subject.Name, sep)
+		})
+		if !seen {
+			missing = append(missing, pattern)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("expected subjects not seen: %s", strings.Join(missing, ", "))
+	}
+	if len(expected) > 0 {
+		logger.Log.Info("All expected subjects seen", "expected", expected)
+	}
+	return nil
+}
+
+// printTree writes the subject tree as indented text with message counts, splitting the
+// count of subjects that are also prefixes into direct and descendant messages
+func printTree(w io.Writer, nodes []*monitor.SubjectTree, depth int) {
+	for _, node := range nodes {
+		indent := strings.Repeat("  ", depth)
+		if node.DirectCount > 0 && node.DescendantCount > 0 {
+			fmt.Fprintf(w, "%s%s (%d: %d direct, %d below)\n", indent, node.Name, node.MessageCount, node.DirectCount, node.DescendantCount)
+		} else {
+			fmt.Fprintf(w, "%s%s (%d)\n", indent, node.Name, node.MessageCount)
+		}
+		printTree(w, node.Children, depth+1)
+	}
+}
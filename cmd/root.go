@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/eallender/nats-ls/internal/config"
 	"github.com/eallender/nats-ls/internal/logger"
@@ -20,10 +21,39 @@ var (
 	cfg *config.Config
 	// Flag to generate default config
 	createConfig bool
+	// Flag to print the effective config
+	printConfig bool
 	// NATS connection override flags
-	natsServer string
-	natsURL    string
-	natsPort   int
+	natsServer      string
+	natsURL         string
+	natsPort        int
+	natsTLSCA       string
+	natsTLSInsecure bool
+	natsTimeout     int
+	natsContext     string
+	// NATS authentication override flags
+	natsUser     string
+	natsPassword string
+	natsToken    string
+	natsNKey     string
+	natsCreds    string
+	// Headless mode flags
+	listSubjects bool
+	listDuration time.Duration
+	listJSON     bool
+	listDOT      bool
+	listExpect   []string
+	// Discovery flags
+	noDiscovery bool
+	// Viewer flags
+	watchSubject string
+	// Metrics endpoint flag
+	metricsAddr string
+	// Appearance flags
+	themeName string
+	noColor   bool
+	// Logging flags
+	logOutput string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -48,6 +78,39 @@ var rootCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		// Print the config after merging the file, environment and flags, then exit. It's
+		// printed before validating so a wrong address can be traced to where it was set
+		if printConfig {
+			if err := printEffectiveConfig(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		// Catch malformed addresses before they surface as a cryptic dial error
+		if err := cfg.ValidateAddress(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if printConfig {
+			return
+		}
+
+		// Expectations are only checked by headless discovery, don't let a CI run pass without it
+		if len(listExpect) > 0 && !listSubjects {
+			fmt.Fprintln(os.Stderr, "Error: --expect requires --list")
+			os.Exit(1)
+		}
+
+		// In headless mode, print the discovered subjects instead of running the TUI
+		if listSubjects {
+			if err := runList(listDuration, listJSON, listDOT, listExpect); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		// Run the TUI
 		if err := tui.Run(cfg); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -66,15 +129,52 @@ func Execute() {
 func init() {
 	// CLI Flags
 	rootCmd.Flags().BoolVar(&createConfig, "generate-config", false, "Generate default config file at ~/.nats-ls/config.yaml and exit")
+	rootCmd.Flags().BoolVar(&printConfig, "print-config", false, "Print the effective config, with config file, environment and flags applied, and exit")
 
 	// NATS connection flags (override config file)
-	rootCmd.Flags().StringVar(&natsServer, "server", "", "NATS server address (overrides config, e.g., 127.0.0.1:4222)")
-	rootCmd.Flags().StringVar(&natsURL, "url", "", "NATS server URL (overrides config, e.g., 127.0.0.1)")
+	rootCmd.Flags().StringVar(&natsServer, "server", "", "NATS server address or comma-separated list of servers (overrides config, e.g., 127.0.0.1:4222)")
+	rootCmd.Flags().StringVar(&natsURL, "url", "", "NATS server URL (overrides config, e.g., 127.0.0.1 or wss://nats.example.com)")
 	rootCmd.Flags().IntVar(&natsPort, "port", 0, "NATS server port (overrides config, e.g., 4222)")
+	rootCmd.Flags().StringVar(&natsTLSCA, "tls-ca", "", "Path to a CA certificate for TLS connections (overrides config)")
+	rootCmd.Flags().BoolVar(&natsTLSInsecure, "tls-insecure", false, "Use TLS without verifying the server certificate, for self-signed dev servers only (overrides config)")
+	rootCmd.Flags().IntVar(&natsTimeout, "timeout", 0, "NATS connection timeout in seconds (overrides config, e.g., 5)")
+	rootCmd.Flags().StringVar(&natsContext, "context", "", "Use the server, credentials and TLS settings of a nats CLI context (overrides config)")
+
+	// NATS authentication flags (override config file)
+	rootCmd.Flags().StringVar(&natsUser, "user", "", "NATS username (overrides config)")
+	rootCmd.Flags().StringVar(&natsPassword, "password", "", "NATS password (overrides config)")
+	rootCmd.Flags().StringVar(&natsToken, "token", "", "NATS authentication token (overrides config)")
+	rootCmd.Flags().StringVar(&natsNKey, "nkey", "", "Path to a NATS NKey seed file (overrides config)")
+	rootCmd.Flags().StringVar(&natsCreds, "creds", "", "Path to a NATS credentials (.creds) file (overrides config)")
+
+	// Headless mode flags
+	rootCmd.Flags().BoolVar(&listSubjects, "list", false, "Run discovery without the TUI, print the subject tree and exit")
+	rootCmd.Flags().DurationVar(&listDuration, "duration", 10*time.Second, "How long to run discovery in --list mode")
+	rootCmd.Flags().BoolVar(&listJSON, "json", false, "Print the subject tree as JSON in --list mode")
+	rootCmd.Flags().BoolVar(&listDOT, "dot", false, "Print the subject tree as a Graphviz DOT graph in --list mode")
+	rootCmd.Flags().StringArrayVar(&listExpect, "expect", nil, "Exit non-zero unless a subject matching this subject or wildcard is seen in --list mode (repeatable, all must match)")
+
+	// Discovery flags
+	rootCmd.Flags().BoolVar(&noDiscovery, "no-discovery", false, "Skip subject discovery and only watch subjects entered with :sub (overrides config)")
+
+	// Viewer flags
+	rootCmd.Flags().StringVar(&watchSubject, "watch", "", "Start watching this subject or wildcard instead of showing the subject tree")
+
+	// Metrics flags
+	rootCmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "Serve Prometheus metrics on this address while running (overrides config, e.g., :9090)")
+
+	// Appearance flags
+	rootCmd.Flags().StringVar(&themeName, "theme", "", "Color theme: default, dracula or solarized (overrides config)")
+	rootCmd.Flags().BoolVar(&noColor, "no-color", false, "Render without color, also set by the NO_COLOR environment variable (overrides config)")
+
+	// Logging flags
+	rootCmd.Flags().StringVar(&logOutput, "log-output", "", "Where to write logs: file, stderr or both (overrides config)")
 
 	// Make --server mutually exclusive with --url and --port
 	rootCmd.MarkFlagsMutuallyExclusive("server", "url")
 	rootCmd.MarkFlagsMutuallyExclusive("server", "port")
+	rootCmd.MarkFlagsMutuallyExclusive("json", "dot")
+	rootCmd.MarkFlagsMutuallyExclusive("watch", "list")
 }
 
 // loadConfig reads in config file and initializes the application
@@ -85,6 +185,15 @@ func loadConfig() error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	// Apply a nats CLI context, which the flags below can still override
+	if natsContext != "" {
+		natsCtx, err := config.LoadNatsContext(natsContext)
+		if err != nil {
+			return err
+		}
+		cfg.ApplyNatsContext(natsCtx)
+	}
+
 	// Apply CLI flag overrides
 	if natsServer != "" {
 		cfg.NatsAddress = natsServer
@@ -95,24 +204,79 @@ func loadConfig() error {
 	if natsPort != 0 {
 		cfg.NatsPort = natsPort
 	}
+	if natsTLSCA != "" {
+		cfg.NatsTLSCAFile = natsTLSCA
+	}
+	if natsTLSInsecure {
+		cfg.NatsTLSInsecure = true
+	}
+	if natsTimeout != 0 {
+		cfg.NatsConnectTimeoutSeconds = natsTimeout
+	}
+	if natsUser != "" {
+		cfg.NatsUsername = natsUser
+	}
+	if natsPassword != "" {
+		cfg.NatsPassword = natsPassword
+	}
+	if natsToken != "" {
+		cfg.NatsToken = natsToken
+	}
+	if natsNKey != "" {
+		cfg.NatsNKeySeedFile = natsNKey
+	}
+	if natsCreds != "" {
+		cfg.NatsCredsFile = natsCreds
+	}
+	if noDiscovery {
+		cfg.NatsDiscoveryEnabled = false
+	}
+	if metricsAddr != "" {
+		cfg.MetricsAddr = metricsAddr
+	}
+	if themeName != "" {
+		cfg.Theme.Name = themeName
+	}
+	// Honor the NO_COLOR convention (https://no-color.org), set to any non-empty value
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		cfg.NoColor = true
+	}
+	if logOutput != "" {
+		cfg.LogOutput = logOutput
+	}
+	cfg.WatchSubject = watchSubject
 
 	// Reconstruct NatsAddress if URL or Port were provided
 	if (natsURL != "" || natsPort != 0) && natsServer == "" {
-		cfg.NatsAddress = fmt.Sprintf("%s:%d", cfg.NatsURL, cfg.NatsPort)
+		cfg.NatsAddress = cfg.AddressFromURL()
 	}
 
 	// Initialize logger
-	if err := logger.Init(cfg.LogLevel); err != nil {
+	if err := logger.Init(cfg.LogLevel, cfg.LogOutput, cfg.LogFormat); err != nil {
 		return fmt.Errorf("failed to initialize logger: %w", err)
 	}
 
-	// Log the loaded configuration
-	configJSON, _ := json.MarshalIndent(cfg, "", "  ")
+	for _, conflict := range cfg.KeyBindings.Conflicts() {
+		logger.Log.Warn("Conflicting keybindings", "conflict", conflict)
+	}
+
+	// Log the loaded configuration with secrets redacted
+	configJSON, _ := json.MarshalIndent(cfg.Redacted(), "", "  ")
 	logger.Log.Debug("Configuration loaded", "config", string(configJSON))
 
 	return nil
 }
 
+// printEffectiveConfig writes the loaded config to stdout as YAML with secrets redacted
+func printEffectiveConfig() error {
+	data, err := cfg.Redacted().YAML()
+	if err != nil {
+		return fmt.Errorf("failed to render config: %w", err)
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
 func generateDefaultConfig() error {
 	// Ensure the config directory exists
 	configDir, err := config.EnsureConfigDir()
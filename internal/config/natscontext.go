@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NatsContext is the subset of a nats CLI context that maps onto our config
+type NatsContext struct {
+	URL      string `json:"url"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+	Creds    string `json:"creds"`
+	NKey     string `json:"nkey"`
+	Cert     string `json:"cert"`
+	Key      string `json:"key"`
+	CA       string `json:"ca"`
+}
+
+// natsContextDir returns where the nats CLI keeps its contexts (~/.config/nats/context)
+func natsContextDir() (string, error) {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configDir = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(configDir, "nats", "context"), nil
+}
+
+// LoadNatsContext reads the named nats CLI context
+func LoadNatsContext(name string) (*NatsContext, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("invalid context name %q", name)
+	}
+
+	dir, err := natsContextDir()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, name+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nats context %q: %w", name, err)
+	}
+
+	var ctx NatsContext
+	if err := json.Unmarshal(data, &ctx); err != nil {
+		return nil, fmt.Errorf("failed to parse nats context %q: %w", name, err)
+	}
+	return &ctx, nil
+}
+
+// ApplyNatsContext overrides the connection, credential and TLS settings with those
+// set in a nats CLI context, leaving anything the context doesn't set untouched
+func (c *Config) ApplyNatsContext(ctx *NatsContext) {
+	if ctx.URL != "" {
+		c.NatsAddress = ctx.URL
+	}
+	if ctx.User != "" {
+		c.NatsUsername = ctx.User
+		c.NatsPassword = ctx.Password
+	}
+	if ctx.Token != "" {
+		c.NatsToken = ctx.Token
+	}
+	if ctx.Creds != "" {
+		c.NatsCredsFile = expandHome(ctx.Creds)
+	}
+	if ctx.NKey != "" {
+		c.NatsNKeySeedFile = expandHome(ctx.NKey)
+	}
+	if ctx.Cert != "" {
+		c.NatsTLSCertFile = expandHome(ctx.Cert)
+	}
+	if ctx.Key != "" {
+		c.NatsTLSKeyFile = expandHome(ctx.Key)
+	}
+	if ctx.CA != "" {
+		c.NatsTLSCAFile = expandHome(ctx.CA)
+	}
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~")
+	if !ok || (rest != "" && rest[0] != '/') {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return homeDir + rest
+}
@@ -5,6 +5,7 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -16,39 +17,90 @@ import (
 
 var Log *slog.Logger
 
-// Init initializes the global logger with automatic rotation
-func Init(logLevel string) error {
+// logPath is the resolved path of the log file, set by Init
+var logPath string
+
+// Log outputs selectable with Init
+const (
+	OutputFile   = "file"
+	OutputStderr = "stderr"
+	OutputBoth   = "both"
+)
+
+// Log formats selectable with Init
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Init initializes the global logger, writing text or JSON to a rotating log file, stderr, or both
+func Init(logLevel string, output string, format string) error {
 	level := GetLevel(logLevel)
 
+	var w io.Writer
+	switch strings.ToLower(output) {
+	case OutputStderr:
+		w = os.Stderr
+	case OutputFile, OutputBoth:
+		fileWriter, err := openLogFile()
+		if err != nil {
+			return err
+		}
+		w = fileWriter
+		if strings.EqualFold(output, OutputBoth) {
+			w = io.MultiWriter(fileWriter, os.Stderr)
+		}
+	default:
+		return fmt.Errorf("unknown log output %q, use %s, %s or %s", output, OutputFile, OutputStderr, OutputBoth)
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case FormatText:
+		handler = slog.NewTextHandler(w, options)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, options)
+	default:
+		return fmt.Errorf("unknown log format %q, use %s or %s", format, FormatText, FormatJSON)
+	}
+	Log = slog.New(handler)
+	slog.SetDefault(Log)
+
+	// Log where the log file is located
+	Log.Info("Logger initialized", "output", output, "format", format, "log_file", logPath, "log_level", logLevel, "max_size_mb", 10)
+
+	return nil
+}
+
+// openLogFile clears the log file and opens it with automatic rotation
+func openLogFile() (io.Writer, error) {
 	logDir, err := config.EnsureConfigDir()
 	if err != nil {
-		return fmt.Errorf("failed to get log directory: %w", err)
+		return nil, fmt.Errorf("failed to get log directory: %w", err)
 	}
 
 	logFile := filepath.Join(logDir, "nls.log")
+	logPath = logFile
 
 	// Clear existing log file on startup
 	if err := os.Truncate(logFile, 0); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to truncate log file: %w", err)
+		return nil, fmt.Errorf("failed to truncate log file: %w", err)
 	}
 
 	// Create rotating file logger with size limits
-	fileWriter := &lumberjack.Logger{
+	return &lumberjack.Logger{
 		Filename:   logFile,
 		MaxSize:    10,    // megabytes - rotate when file reaches this size
 		MaxBackups: 0,     // don't keep any old backups
 		MaxAge:     0,     // don't delete based on age
 		Compress:   false, // don't compress old logs
-	}
-
-	handler := slog.NewTextHandler(fileWriter, &slog.HandlerOptions{Level: level})
-	Log = slog.New(handler)
-	slog.SetDefault(Log)
-
-	// Log where the log file is located
-	Log.Info("Logger initialized", "log_file", logFile, "level", logLevel, "max_size_mb", 10)
+	}, nil
+}
 
-	return nil
+// LogPath returns the path of the log file, or "" if the logger isn't initialized or only logs to stderr
+func LogPath() string {
+	return logPath
 }
 
 // Gets the log level from the given string
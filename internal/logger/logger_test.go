@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package logger
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestInitFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		valid  func(line string) bool
+	}{
+		{FormatText, func(line string) bool {
+			return strings.Contains(line, `level=INFO msg="Logger initialized"`)
+		}},
+		{FormatJSON, func(line string) bool {
+			var entry map[string]any
+			return json.Unmarshal([]byte(line), &entry) == nil && entry["level"] == "INFO" && entry["msg"] == "Logger initialized"
+		}},
+	}
+	for _, tt := range tests {
+		t.Setenv("NLS_CONFIG_DIR", t.TempDir())
+		if err := Init("info", OutputFile, tt.format); err != nil {
+			t.Fatalf("Init() with format %s: %v", tt.format, err)
+		}
+
+		data, err := os.ReadFile(LogPath())
+		if err != nil {
+			t.Fatal(err)
+		}
+		line, _, _ := strings.Cut(string(data), "\n")
+		if !tt.valid(line) {
+			t.Errorf("format %s wrote %q", tt.format, line)
+		}
+	}
+}
+
+func TestInitUnknownFormat(t *testing.T) {
+	err := Init("info", OutputStderr, "xml")
+	if err == nil || err.Error() != `unknown log format "xml", use text or json` {
+		t.Errorf("Init() with format xml = %v, want unknown log format error", err)
+	}
+}
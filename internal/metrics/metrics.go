@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eallender/nats-ls/internal/logger"
+	"github.com/eallender/nats-ls/internal/monitor"
+	"github.com/nats-io/nats.go"
+)
+
+// shutdownTimeout bounds how long Stop waits for in-flight scrapes
+const shutdownTimeout = 2 * time.Second
+
+// labelEscaper escapes label values for the Prometheus text format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Server exposes discovery stats on /metrics in the Prometheus text format
+type Server struct {
+	srv       *http.Server
+	mu        sync.RWMutex
+	nc        *nats.Conn
+	discovery *monitor.Discovery
+}
+
+func NewServer(addr string) *Server {
+	s := &Server{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	s.srv = &http.Server{Addr: addr, Handler: mux}
+	return s
+}
+
+// SetSource points the metrics at a connection and its discovery, which change on reconnect
+func (s *Server) SetSource(nc *nats.Conn, discovery *monitor.Discovery) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nc = nc
+	s.discovery = discovery
+}
+
+// Starts serving metrics in the background
+func (s *Server) Start() {
+	go func() {
+		logger.Log.Info("Serving metrics", "address", s.srv.Addr)
+		if err := s.srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Log.Warn("Metrics server failed", "address", s.srv.Addr, "error", err)
+		}
+	}()
+}
+
+// Stop shuts down the metrics server
+func (s *Server) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := s.srv.Shutdown(ctx); err != nil {
+		logger.Log.Warn("Failed to stop metrics server", "error", err)
+	}
+	logger.Log.Debug("Metrics server has been stopped")
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	s.mu.RLock()
+	nc, discovery := s.nc, s.discovery
+	s.mu.RUnlock()
+
+	connected := 0
+	if nc != nil && nc.IsConnected() {
+		connected = 1
+	}
+	writeMetric(w, "nats_ls_connected", "gauge", "Whether nats-ls is connected to NATS", connected)
+
+	if discovery == nil {
+		return
+	}
+
+	subjects := discovery.Snapshot()
+	sort.Slice(subjects, func(i, j int) bool {
+		return subjects[i].Name < subjects[j].Name
+	})
+
+	writeMetric(w, "nats_ls_messages_total", "counter", "Messages observed by discovery", discovery.TotalMessages())
+	writeMetric(w, "nats_ls_subjects", "gauge", "Subjects currently tracked by discovery", len(subjects))
+	writeMetric(w, "nats_ls_subjects_evicted_total", "counter", "Subjects evicted to stay within the subject limit", discovery.EvictedSubjects())
+
+	fmt.Fprintf(w, "# HELP nats_ls_subject_messages_total Messages observed per subject\n")
+	fmt.Fprintf(w, "# TYPE nats_ls_subject_messages_total counter\n")
+	for _, subject := range subjects {
+		fmt.Fprintf(w, "nats_ls_subject_messages_total{subject=\"%s\"} %d\n", labelEscaper.Replace(subject.Name), subject.MessageCount)
+	}
+
+	fmt.Fprintf(w, "# HELP nats_ls_subject_bytes_total Payload bytes observed per subject\n")
+	fmt.Fprintf(w, "# TYPE nats_ls_subject_bytes_total counter\n")
+	for _, subject := range subjects {
+		fmt.Fprintf(w, "nats_ls_subject_bytes_total{subject=\"%s\"} %d\n", labelEscaper.Replace(subject.Name), subject.TotalBytes)
+	}
+}
+
+// writeMetric writes a single unlabeled metric with its help and type lines
+func writeMetric[T int | int64](w io.Writer, name, kind, help string, value T) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", name, help, name, kind, name, value)
+}
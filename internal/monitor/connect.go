@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package monitor
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/eallender/nats-ls/internal/config"
+	"github.com/eallender/nats-ls/internal/logger"
+	"github.com/nats-io/nats.go"
+)
+
+// Connect connects to NATS using the options derived from the config
+func Connect(cfg *config.Config) (*nats.Conn, error) {
+	opts, err := buildNatsOptions(cfg)
+	if err != nil {
+		logger.Log.Error("Invalid NATS connection settings", "error", err)
+		return nil, err
+	}
+
+	// nats.Connect accepts a comma-separated list and fails over between them
+	nc, err := nats.Connect(strings.Join(cfg.NatsServerList(), ","), opts...)
+	if err != nil {
+		Connections.Record(ConnectionFailed, err.Error())
+		return nil, err
+	}
+	Connections.Record(ConnectionConnected, nc.ConnectedUrlRedacted())
+	return nc, nil
+}
+
+// drainPollInterval is how often Drain checks whether the connection has closed
+const drainPollInterval = 10 * time.Millisecond
+
+// Drain flushes pending publishes and subscriptions before closing the connection,
+// falling back to an immediate close if draining fails or takes longer than timeout
+func Drain(nc *nats.Conn, timeout time.Duration) {
+	if err := nc.Drain(); err != nil {
+		logger.Log.Warn("Failed to drain NATS connection, closing", "error", err)
+		nc.Close()
+		return
+	}
+
+	deadline := time.Now().Add(timeout)
+	for !nc.IsClosed() {
+		if time.Now().After(deadline) {
+			logger.Log.Warn("Timed out draining NATS connection, closing", "timeout", timeout)
+			nc.Close()
+			return
+		}
+		time.Sleep(drainPollInterval)
+	}
+	logger.Log.Debug("NATS connection drained")
+}
+
+// buildNatsOptions translates the config into NATS connection options
+func buildNatsOptions(cfg *config.Config) ([]nats.Option, error) {
+	opts := []nats.Option{
+		nats.MaxReconnects(cfg.NatsMaxReconnects),
+		nats.ReconnectWait(time.Duration(cfg.NatsReconnectWaitSeconds) * time.Second),
+		nats.ReconnectJitter(
+			time.Duration(cfg.NatsReconnectJitterMs)*time.Millisecond,
+			time.Duration(cfg.NatsReconnectJitterTLSMs)*time.Millisecond,
+		),
+		nats.DisconnectErrHandler(func(nc *nats.Conn, err error) {
+			if err != nil {
+				logger.Log.Warn("Disconnected from NATS", "error", err)
+				Connections.Record(ConnectionDisconnected, err.Error())
+			} else {
+				logger.Log.Info("Disconnected from NATS")
+				Connections.Record(ConnectionDisconnected, "")
+			}
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			logger.Log.Info("Reconnected to NATS", "address", nc.ConnectedUrl())
+			Connections.Record(ConnectionReconnected, nc.ConnectedUrlRedacted())
+		}),
+		nats.ClosedHandler(func(nc *nats.Conn) {
+			logger.Log.Debug("NATS connection closed")
+			Connections.Record(ConnectionClosed, "")
+		}),
+		// Without a handler the client prints async errors, like slow consumers, over the TUI
+		nats.ErrorHandler(func(nc *nats.Conn, sub *nats.Subscription, err error) {
+			if sub != nil {
+				logger.Log.Warn("NATS subscription error", "subject", sub.Subject, "error", err)
+				return
+			}
+			logger.Log.Warn("NATS error", "error", err)
+		}),
+	}
+
+	// Bound the dial so an unreachable server doesn't stall the retry loop
+	if cfg.NatsConnectTimeoutSeconds > 0 {
+		opts = append(opts, nats.Timeout(time.Duration(cfg.NatsConnectTimeoutSeconds)*time.Second))
+	}
+
+	// Match the library's own drain deadline to the one Drain waits for
+	if cfg.NatsDrainTimeoutSeconds > 0 {
+		opts = append(opts, nats.DrainTimeout(cfg.NatsDrainTimeout()))
+	}
+
+	tlsOpts, err := buildTLSOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, tlsOpts...)
+
+	authOpts, err := buildAuthOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, authOpts...)
+
+	return opts, nil
+}
+
+// buildAuthOptions returns the authentication options configured for NATS
+func buildAuthOptions(cfg *config.Config) ([]nats.Option, error) {
+	var opts []nats.Option
+
+	// Creds files take precedence over username/password. The file is read on
+	// every connect, so rotated credentials are picked up by the retry loop
+	if cfg.NatsCredsFile != "" {
+		if _, err := os.Stat(cfg.NatsCredsFile); err != nil {
+			return nil, fmt.Errorf("failed to read creds file: %w", err)
+		}
+		if cfg.NatsUsername != "" || cfg.NatsPassword != "" {
+			logger.Log.Warn("NATS creds file configured, ignoring username/password")
+		}
+		opts = append(opts, nats.UserCredentials(cfg.NatsCredsFile))
+	} else if cfg.NatsUsername != "" {
+		if cfg.NatsPassword == "" {
+			logger.Log.Warn("NATS username provided without a password", "username", cfg.NatsUsername)
+		}
+		opts = append(opts, nats.UserInfo(cfg.NatsUsername, cfg.NatsPassword))
+	}
+
+	if cfg.NatsToken != "" {
+		opts = append(opts, nats.Token(cfg.NatsToken))
+	}
+
+	if cfg.NatsNKeySeedFile != "" {
+		nkeyOpt, err := nats.NkeyOptionFromSeed(cfg.NatsNKeySeedFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load NKey seed: %w", err)
+		}
+		opts = append(opts, nkeyOpt)
+	}
+
+	return opts, nil
+}
+
+// buildTLSOptions returns the TLS options, if TLS is enabled in the config
+func buildTLSOptions(cfg *config.Config) ([]nats.Option, error) {
+	if !cfg.TLSEnabled() {
+		return nil, nil
+	}
+
+	opts := []nats.Option{nats.Secure()}
+	if cfg.NatsTLSInsecure {
+		logger.Log.Warn("TLS certificate verification is disabled (nats_tls_insecure), never use this in production")
+		opts = []nats.Option{nats.Secure(&tls.Config{InsecureSkipVerify: true})}
+	}
+
+	if cfg.NatsTLSCAFile != "" {
+		// Check the CA file up front so a bad path yields a clear error
+		if _, err := os.ReadFile(cfg.NatsTLSCAFile); err != nil {
+			return nil, fmt.Errorf("failed to read TLS CA file: %w", err)
+		}
+		opts = append(opts, nats.RootCAs(cfg.NatsTLSCAFile))
+	}
+
+	if cfg.NatsTLSCertFile != "" || cfg.NatsTLSKeyFile != "" {
+		if cfg.NatsTLSCertFile == "" || cfg.NatsTLSKeyFile == "" {
+			return nil, fmt.Errorf("both a TLS cert file and key file are required for client certificates")
+		}
+		opts = append(opts, nats.ClientCert(cfg.NatsTLSCertFile, cfg.NatsTLSKeyFile))
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package monitor
+
+import (
+	"sync"
+	"time"
+)
+
+// maxConnectionEvents bounds how many connection state changes are remembered
+const maxConnectionEvents = 50
+
+// ConnectionState is a state the NATS connection moved into
+type ConnectionState string
+
+const (
+	ConnectionConnected    ConnectionState = "connected"
+	ConnectionFailed       ConnectionState = "connect failed"
+	ConnectionDisconnected ConnectionState = "disconnected"
+	ConnectionReconnected  ConnectionState = "reconnected"
+	ConnectionClosed       ConnectionState = "closed"
+)
+
+// ConnectionEvent is a connection state change, repeated Count times in a row
+type ConnectionEvent struct {
+	Time   time.Time // When the state was last recorded
+	State  ConnectionState
+	Detail string // Server address or error, if any
+	Count  int    // Times the same state and detail were recorded in a row, such as retries failing alike
+}
+
+// ConnectionHistory keeps the most recent connection state changes in a ring buffer
+type ConnectionHistory struct {
+	mu     sync.Mutex
+	events []ConnectionEvent // Fixed size ring, slots past count are empty
+	head   int               // Index of the oldest event
+	count  int
+}
+
+// Connections records the state changes of every connection made by Connect
+var Connections = NewConnectionHistory(maxConnectionEvents)
+
+// Creates a new Connection History keeping up to limit events
+func NewConnectionHistory(limit int) *ConnectionHistory {
+	return &ConnectionHistory{events: make([]ConnectionEvent, max(limit, 1))}
+}
+
+// Record adds a state change, dropping the oldest if at capacity. A change repeating the
+// latest one's state and detail is folded into it, so a reconnect loop failing the same way
+// every attempt doesn't push out the event that started the outage
+func (h *ConnectionHistory) Record(state ConnectionState, detail string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	if h.count > 0 {
+		latest := &h.events[(h.head+h.count-1)%len(h.events)]
+		if latest.State == state && latest.Detail == detail {
+			latest.Time = now
+			latest.Count++
+			return
+		}
+	}
+
+	event := ConnectionEvent{Time: now, State: state, Detail: detail, Count: 1}
+	if h.count == len(h.events) {
+		h.events[h.head] = event
+		h.head = (h.head + 1) % len(h.events)
+		return
+	}
+	h.events[(h.head+h.count)%len(h.events)] = event
+	h.count++
+}
+
+// Recent returns up to n of the most recent events, newest first
+func (h *ConnectionHistory) Recent(n int) []ConnectionEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	n = min(n, h.count)
+	result := make([]ConnectionEvent, n)
+	for i := range result {
+		result[i] = h.events[(h.head+h.count-1-i)%len(h.events)]
+	}
+	return result
+}
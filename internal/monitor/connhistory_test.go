@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package monitor
+
+import (
+	"fmt"
+	"testing"
+)
+
+// describe summarizes events as "state:detail×count", newest first
+func describe(events []ConnectionEvent) string {
+	var parts []string
+	for _, event := range events {
+		parts = append(parts, fmt.Sprintf("%s:%s×%d", event.State, event.Detail, event.Count))
+	}
+	return fmt.Sprint(parts)
+}
+
+func TestConnectionHistoryCollapsesRepeats(t *testing.T) {
+	history := NewConnectionHistory(5)
+	history.Record(ConnectionConnected, "a")
+	history.Record(ConnectionDisconnected, "eof")
+	for range 10 {
+		history.Record(ConnectionFailed, "refused")
+	}
+	history.Record(ConnectionFailed, "timeout")
+	history.Record(ConnectionFailed, "timeout")
+
+	want := "[connect failed:timeout×2 connect failed:refused×10 disconnected:eof×1 connected:a×1]"
+	if got := describe(history.Recent(5)); got != want {
+		t.Errorf("Recent() = %s, want %s", got, want)
+	}
+}
+
+func TestConnectionHistoryWraparound(t *testing.T) {
+	history := NewConnectionHistory(3)
+	for i := range 5 {
+		history.Record(ConnectionConnected, fmt.Sprint(i))
+	}
+
+	if got := describe(history.Recent(10)); got != "[connected:4×1 connected:3×1 connected:2×1]" {
+		t.Errorf("Recent(10) = %s, want the last 3 newest first", got)
+	}
+	if got := describe(history.Recent(2)); got != "[connected:4×1 connected:3×1]" {
+		t.Errorf("Recent(2) = %s, want the last 2 newest first", got)
+	}
+}
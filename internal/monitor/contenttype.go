@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ContentType is a guess at the format of a subject's payloads
+type ContentType string
+
+const (
+	ContentTypeUnknown ContentType = ""
+	ContentTypeEmpty   ContentType = "empty"
+	ContentTypeJSON    ContentType = "json"
+	ContentTypeText    ContentType = "text"
+	ContentTypeBinary  ContentType = "binary" // Likely protobuf, msgpack or another binary encoding
+)
+
+// contentTypeResampleInterval is how many messages pass between payload samples on a subject
+const contentTypeResampleInterval = 1000
+
+// GuessContentType guesses the format of a payload from its bytes
+func GuessContentType(data []byte) ContentType {
+	if len(data) == 0 {
+		return ContentTypeEmpty
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed) {
+		return ContentTypeJSON
+	}
+
+	if !utf8.Valid(data) {
+		return ContentTypeBinary
+	}
+	for _, r := range string(data) {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return ContentTypeBinary
+		}
+	}
+	return ContentTypeText
+}
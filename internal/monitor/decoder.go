@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Built-in decoder names
+const (
+	DecoderRaw  = "raw"
+	DecoderJSON = "json"
+)
+
+// Decoder renders a payload as text for display. Decoders for other formats, such as
+// protobuf with a descriptor, msgpack or Avro, are added with DecoderRegistry.Register
+type Decoder interface {
+	Decode(subject string, data []byte) (string, error)
+}
+
+// DecoderFunc adapts a function to a Decoder
+type DecoderFunc func(subject string, data []byte) (string, error)
+
+// Decode calls f
+func (f DecoderFunc) Decode(subject string, data []byte) (string, error) {
+	return f(subject, data)
+}
+
+// RawDecoder shows payloads as they are
+var RawDecoder = DecoderFunc(func(_ string, data []byte) (string, error) {
+	return string(data), nil
+})
+
+// JSONDecoder indents JSON payloads, failing on anything else
+var JSONDecoder = DecoderFunc(func(_ string, data []byte) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+})
+
+// decoderRule decodes subjects matching a wildcard pattern with a named decoder
+type decoderRule struct {
+	pattern string
+	name    string
+}
+
+// DecoderRegistry picks a decoder for each subject from rules matching NATS wildcard
+// patterns, in the order they were added, and decodes the rest with a fallback
+type DecoderRegistry struct {
+	mu       sync.RWMutex
+	decoders map[string]Decoder
+	rules    []decoderRule
+	fallback string
+	sep      string // Separates the tokens rules match on
+}
+
+// NewDecoderRegistry creates a registry with the built-in raw and json decoders, matching
+// rules against subjects split on sep and falling back to json for subjects without a rule
+func NewDecoderRegistry(sep string) *DecoderRegistry {
+	return &DecoderRegistry{
+		decoders: map[string]Decoder{
+			DecoderRaw:  RawDecoder,
+			DecoderJSON: JSONDecoder,
+		},
+		fallback: DecoderJSON,
+		sep:      sep,
+	}
+}
+
+// Register adds a decoder, replacing any decoder with the same name
+func (r *DecoderRegistry) Register(name string, decoder Decoder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.decoders[name] = decoder
+}
+
+// Use decodes subjects matching pattern with the named decoder, unless an earlier rule matches
+func (r *DecoderRegistry) Use(pattern, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.decoders[name]; !ok {
+		return fmt.Errorf("unknown decoder %q", name)
+	}
+	r.rules = append(r.rules, decoderRule{pattern: pattern, name: name})
+	return nil
+}
+
+// Match returns the name of the decoder chosen by a rule for subject, false if no rule matches
+func (r *DecoderRegistry) Match(subject string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, rule := range r.rules {
+		if MatchSubject(rule.pattern, subject, r.sep) {
+			return rule.name, true
+		}
+	}
+	return "", false
+}
+
+// Decode renders a payload with the subject's decoder, or the fallback if no rule matches,
+// and returns the name of the decoder used. Payloads the decoder fails on are shown raw
+func (r *DecoderRegistry) Decode(subject string, data []byte) (string, string) {
+	name, ok := r.Match(subject)
+	if !ok {
+		name = r.fallback
+	}
+
+	r.mu.RLock()
+	decoder := r.decoders[name]
+	r.mu.RUnlock()
+
+	text, err := decoder.Decode(subject, data)
+	if err != nil {
+		return string(data), DecoderRaw
+	}
+	return text, name
+}
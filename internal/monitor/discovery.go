@@ -5,41 +5,70 @@ package monitor
 
 import (
 	"context"
+	"runtime/debug"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/eallender/nats-ls/internal/logger"
 	"github.com/nats-io/nats.go"
 )
 
+// maxStaleSweepInterval bounds how long a stale subject can outlive its TTL
+const maxStaleSweepInterval = 10 * time.Second
+
 type Discovery struct {
-	nc    *nats.Conn
-	sub   *nats.Subscription
-	mu    sync.Mutex
-	store *SubjectStore
+	nc             *nats.Conn
+	sub            *nats.Subscription
+	mu             sync.Mutex
+	store          *SubjectStore
+	ignorePrefixes []string
+	showIgnored    atomic.Bool
+	staleTTL       time.Duration      // Subjects not seen for this long are purged, 0 to keep them forever
+	cancelSweeper  context.CancelFunc // Stops the stale subject sweeper, nil when not running
+	panics         atomic.Int64       // Messages skipped because processing them panicked
+	process        func(*nats.Msg)    // Records a delivered message, replaceable in tests
 }
 
-func NewDiscovery(nc *nats.Conn) *Discovery {
-	return &Discovery{
-		nc:    nc,
-		store: &SubjectStore{},
+func NewDiscovery(nc *nats.Conn, rateWindowSeconds int, historySeconds int, maxSubjects int, previewBytes int, staleTTL time.Duration, ignorePrefixes []string) *Discovery {
+	d := &Discovery{
+		nc:             nc,
+		store:          NewSubjectStore(rateWindowSeconds, historySeconds, maxSubjects, previewBytes),
+		ignorePrefixes: ignorePrefixes,
+		staleTTL:       staleTTL,
 	}
+	d.process = d.record
+	return d
 }
 
-// Starts NATS subject discovery
-func (d *Discovery) Start(ctx context.Context, maxMessages int, maxStorageMB int) error {
+// Starts NATS subject discovery. With a queue group, each message goes to only one of the
+// instances in the group, sharing the load but leaving each with a subset of the traffic
+func (d *Discovery) Start(ctx context.Context, maxMessages int, maxStorageMB int, queueGroup string) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
 	var err error
-	d.sub, err = d.nc.Subscribe(">", func(msg *nats.Msg) {
-		d.store.Record(msg.Subject)
-	})
+	if queueGroup != "" {
+		d.sub, err = d.nc.QueueSubscribe(">", queueGroup, d.handleMessage)
+	} else {
+		d.sub, err = d.nc.Subscribe(">", d.handleMessage)
+	}
 	if err != nil {
 		return err
 	}
+	if queueGroup != "" {
+		logger.Log.Info("Discovering subjects in a queue group, only a share of messages is seen", "queue_group", queueGroup)
+	}
 
 	d.sub.SetPendingLimits(maxMessages, maxStorageMB*1024*1024)
 
+	if d.staleTTL > 0 {
+		var sweepCtx context.Context
+		sweepCtx, d.cancelSweeper = context.WithCancel(ctx)
+		go d.sweepStale(sweepCtx)
+	}
+
 	go func() {
 		<-ctx.Done()
 		d.Stop()
@@ -48,11 +77,117 @@ func (d *Discovery) Start(ctx context.Context, maxMessages int, maxStorageMB int
 	return nil
 }
 
+// handleMessage processes a message delivered to the discovery subscription
+func (d *Discovery) handleMessage(msg *nats.Msg) {
+	defer d.recoverMessage(msg)
+	d.process(msg)
+}
+
+// record tracks a message's subject unless it's ignored
+func (d *Discovery) record(msg *nats.Msg) {
+	if !d.showIgnored.Load() && d.IsIgnored(msg.Subject) {
+		return
+	}
+	d.store.Record(msg)
+}
+
+// recoverMessage logs and counts a panic while processing msg, so one bad message doesn't
+// end the subscription's delivery of the rest
+func (d *Discovery) recoverMessage(msg *nats.Msg) {
+	if r := recover(); r != nil {
+		d.panics.Add(1)
+		logger.Log.Error("Discovery failed to process message", "subject", msg.Subject, "panic", r, "stack", string(debug.Stack()))
+	}
+}
+
+// ProcessingErrors returns the number of messages skipped because processing them panicked
+func (d *Discovery) ProcessingErrors() int64 {
+	return d.panics.Load()
+}
+
+// sweepStale periodically purges subjects that haven't been seen within the stale TTL
+func (d *Discovery) sweepStale(ctx context.Context) {
+	ticker := time.NewTicker(min(d.staleTTL, maxStaleSweepInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if purged := d.store.PurgeStale(now.Add(-d.staleTTL)); purged > 0 {
+				logger.Log.Debug("Purged stale subjects", "count", purged, "ttl", d.staleTTL)
+			}
+		}
+	}
+}
+
+// IsIgnored reports whether a subject matches one of the ignored prefixes
+func (d *Discovery) IsIgnored(subject string) bool {
+	for _, prefix := range d.ignorePrefixes {
+		if strings.HasPrefix(subject, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetShowIgnored toggles whether subjects with ignored prefixes are recorded
+func (d *Discovery) SetShowIgnored(show bool) {
+	d.showIgnored.Store(show)
+}
+
+// ShowIgnored reports whether subjects with ignored prefixes are being recorded
+func (d *Discovery) ShowIgnored() bool {
+	return d.showIgnored.Load()
+}
+
 // GetAllSubjects returns all discovered subjects
 func (d *Discovery) GetAllSubjects() []*SubjectInfo {
 	return d.store.All()
 }
 
+// Snapshot returns a point-in-time copy of all discovered subjects
+func (d *Discovery) Snapshot() []SubjectSnapshot {
+	subjects := d.store.All()
+	result := make([]SubjectSnapshot, 0, len(subjects))
+	for _, subject := range subjects {
+		result = append(result, subject.Snapshot())
+	}
+	return result
+}
+
+// TotalMessages returns the number of messages recorded by discovery
+func (d *Discovery) TotalMessages() int64 {
+	return d.store.Total()
+}
+
+// Dropped returns how many messages discovery has dropped since it started because they
+// arrived faster than they were recorded and overflowed the pending limits
+func (d *Discovery) Dropped() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.sub == nil {
+		return 0
+	}
+	dropped, err := d.sub.Dropped()
+	if err != nil {
+		return 0
+	}
+	return dropped
+}
+
+// EvictedSubjects returns the number of subjects evicted to stay within the subject limit
+func (d *Discovery) EvictedSubjects() int64 {
+	return d.store.Evicted()
+}
+
+// SizeBytes estimates the memory held by the discovered subjects
+func (d *Discovery) SizeBytes() int {
+	return d.store.SizeBytes()
+}
+
 // GetSubject returns info for a specific subject
 func (d *Discovery) GetSubject(subject string) (*SubjectInfo, bool) {
 	return d.store.Get(subject)
@@ -67,5 +202,9 @@ func (d *Discovery) Stop() {
 		d.sub.Unsubscribe()
 		d.sub = nil
 	}
+	if d.cancelSweeper != nil {
+		d.cancelSweeper()
+		d.cancelSweeper = nil
+	}
 	logger.Log.Debug("Discovery has been stopped")
 }
@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package monitor
+
+import (
+	"testing"
+
+	"github.com/nats-io/nats.go"
+)
+
+func TestDiscoveryRecoversFromPanic(t *testing.T) {
+	d := NewDiscovery(nil, 10, 10, 0, 0, 0, nil)
+	d.process = func(msg *nats.Msg) {
+		if msg.Subject == "orders.bad" {
+			panic("bad message")
+		}
+		d.record(msg)
+	}
+
+	d.handleMessage(&nats.Msg{Subject: "orders.bad"})
+	d.handleMessage(&nats.Msg{Subject: "orders.good"})
+
+	if errors := d.ProcessingErrors(); errors != 1 {
+		t.Errorf("ProcessingErrors() = %d, want 1", errors)
+	}
+	if _, ok := d.GetSubject("orders.good"); !ok {
+		t.Error("message after the panic wasn't processed")
+	}
+}
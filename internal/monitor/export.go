@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package monitor
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// subjectExport is the exported representation of a subject
+type subjectExport struct {
+	Name         string    `json:"name"`
+	MessageCount int64     `json:"message_count"`
+	FirstSeen    time.Time `json:"first_seen"`
+	LastSeen     time.Time `json:"last_seen"`
+}
+
+// ExportSubjects writes the subjects to w as a JSON array, sorted by name
+func ExportSubjects(w io.Writer, subjects []*SubjectInfo) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(toSubjectExports(subjects))
+}
+
+// ExportSubjectsCSV writes the subjects to w as CSV with a header row, sorted by name
+func ExportSubjectsCSV(w io.Writer, subjects []*SubjectInfo) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"name", "message_count", "first_seen", "last_seen"}); err != nil {
+		return err
+	}
+
+	for _, subject := range toSubjectExports(subjects) {
+		record := []string{
+			subject.Name,
+			strconv.FormatInt(subject.MessageCount, 10),
+			subject.FirstSeen.Format(time.RFC3339Nano),
+			subject.LastSeen.Format(time.RFC3339Nano),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// ExportSubjectsDOT writes the subject hierarchy, split on sep, to w as a Graphviz DOT graph
+func ExportSubjectsDOT(w io.Writer, subjects []*SubjectInfo, sep string) error {
+	snapshots := make([]SubjectSnapshot, 0, len(subjects))
+	for _, subject := range subjects {
+		snapshots = append(snapshots, subject.Snapshot())
+	}
+	return BuildSubjectTree(snapshots, sep).WriteDOT(w)
+}
+
+// toSubjectExports copies the subjects' current stats, sorted by name
+func toSubjectExports(subjects []*SubjectInfo) []subjectExport {
+	exports := make([]subjectExport, 0, len(subjects))
+	for _, subject := range subjects {
+		snapshot := subject.Snapshot()
+		exports = append(exports, subjectExport{
+			Name:         snapshot.Name,
+			MessageCount: snapshot.MessageCount,
+			FirstSeen:    snapshot.FirstSeen,
+			LastSeen:     snapshot.LastSeen,
+		})
+	}
+
+	sort.Slice(exports, func(i, j int) bool {
+		return exports[i].Name < exports[j].Name
+	})
+	return exports
+}
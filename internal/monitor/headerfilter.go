@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package monitor
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+)
+
+// HeaderFilter matches messages carrying a header, optionally with a specific value
+type HeaderFilter struct {
+	Key   string
+	Value string // Empty matches any value
+}
+
+// ParseHeaderFilter parses "key" or "key=value"
+func ParseHeaderFilter(s string) (*HeaderFilter, error) {
+	key, value, _ := strings.Cut(s, "=")
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, fmt.Errorf("missing header name")
+	}
+	return &HeaderFilter{Key: key, Value: strings.TrimSpace(value)}, nil
+}
+
+// String returns the filter in the form it was parsed from
+func (f *HeaderFilter) String() string {
+	if f.Value == "" {
+		return f.Key
+	}
+	return f.Key + "=" + f.Value
+}
+
+// Matches reports whether the headers carry the filter's header and value. Header
+// names are compared case-insensitively since publishers rarely agree on casing
+func (f *HeaderFilter) Matches(headers nats.Header) bool {
+	for key, values := range headers {
+		if !strings.EqualFold(key, f.Key) {
+			continue
+		}
+		if f.Value == "" || slices.Contains(values, f.Value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package monitor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/eallender/nats-ls/internal/logger"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// jetStreamRequestTimeout bounds each JetStream API request
+const jetStreamRequestTimeout = 5 * time.Second
+
+// Stream describes a JetStream stream and the subjects it captures
+type Stream struct {
+	Name      string
+	Subjects  []string
+	Messages  uint64
+	Bytes     uint64
+	Consumers int
+	LastSeen  time.Time
+}
+
+// Consumer describes the delivery state of a JetStream consumer
+type Consumer struct {
+	Name           string
+	NumPending     uint64
+	NumAckPending  int
+	NumRedelivered int
+	AckFloor       uint64 // Stream sequence of the last message acknowledged in order
+}
+
+type JetStream struct {
+	js      jetstream.JetStream
+	mu      sync.RWMutex
+	streams []Stream
+	err     error
+	cancel  context.CancelFunc
+}
+
+func NewJetStream(nc *nats.Conn) (*JetStream, error) {
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return nil, err
+	}
+	return &JetStream{js: js}, nil
+}
+
+// Starts polling the JetStream API for streams at the given interval
+func (j *JetStream) Start(ctx context.Context, interval time.Duration) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	j.mu.Lock()
+	j.cancel = cancel
+	j.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := j.Refresh(ctx); err != nil {
+				logger.Log.Debug("Failed to list JetStream streams", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Refresh lists all streams from the JetStream API
+func (j *JetStream) Refresh(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, jetStreamRequestTimeout)
+	defer cancel()
+
+	var streams []Stream
+	lister := j.js.ListStreams(ctx)
+	for info := range lister.Info() {
+		streams = append(streams, Stream{
+			Name:      info.Config.Name,
+			Subjects:  info.Config.Subjects,
+			Messages:  info.State.Msgs,
+			Bytes:     info.State.Bytes,
+			Consumers: info.State.Consumers,
+			LastSeen:  info.State.LastTime,
+		})
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := lister.Err(); err != nil {
+		j.err = err
+		return err
+	}
+	j.streams = streams
+	j.err = nil
+	return nil
+}
+
+// GetStreams returns the streams found by the last successful refresh
+func (j *JetStream) GetStreams() []Stream {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	result := make([]Stream, len(j.streams))
+	copy(result, j.streams)
+	return result
+}
+
+// GetConsumers lists the consumers of the given stream
+func (j *JetStream) GetConsumers(ctx context.Context, stream string) ([]Consumer, error) {
+	ctx, cancel := context.WithTimeout(ctx, jetStreamRequestTimeout)
+	defer cancel()
+
+	s, err := j.js.Stream(ctx, stream)
+	if err != nil {
+		return nil, err
+	}
+
+	consumers := []Consumer{}
+	lister := s.ListConsumers(ctx)
+	for info := range lister.Info() {
+		consumers = append(consumers, Consumer{
+			Name:           info.Name,
+			NumPending:     info.NumPending,
+			NumAckPending:  info.NumAckPending,
+			NumRedelivered: info.NumRedelivered,
+			AckFloor:       info.AckFloor.Stream,
+		})
+	}
+	if err := lister.Err(); err != nil {
+		return nil, err
+	}
+
+	return consumers, nil
+}
+
+// IsJetStreamNotEnabled reports whether err means JetStream is unavailable on the server
+func IsJetStreamNotEnabled(err error) bool {
+	return errors.Is(err, jetstream.ErrJetStreamNotEnabled) ||
+		errors.Is(err, jetstream.ErrJetStreamNotEnabledForAccount)
+}
+
+// Err returns the error from the last refresh, if any
+func (j *JetStream) Err() error {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	return j.err
+}
+
+// Stop stops polling for streams
+func (j *JetStream) Stop() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.cancel != nil {
+		j.cancel()
+		j.cancel = nil
+	}
+	logger.Log.Debug("JetStream monitor has been stopped")
+}
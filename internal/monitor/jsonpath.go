@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONPath is a simple JSON path such as $.order.items[0].id, supporting object keys
+// (.key or ['key']) and array indexes ([n])
+type JSONPath struct {
+	expr  string
+	steps []pathStep
+}
+
+// pathStep is a single object key or array index in a JSONPath
+type pathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// ParseJSONPath parses a JSON path expression, the leading $ is optional
+func ParseJSONPath(expr string) (*JSONPath, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(expr), "$")
+	path := &JSONPath{expr: expr}
+
+	// Allow "order.id" as shorthand for "$.order.id"
+	if rest != "" && rest[0] != '.' && rest[0] != '[' {
+		rest = "." + rest
+	}
+
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid JSON path %q: empty key", expr)
+			}
+			path.steps = append(path.steps, pathStep{key: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q: missing ]", expr)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+
+			if unquoted, ok := unquoteKey(inner); ok {
+				path.steps = append(path.steps, pathStep{key: unquoted})
+				continue
+			}
+			index, err := strconv.Atoi(inner)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q: bad index %q", expr, inner)
+			}
+			path.steps = append(path.steps, pathStep{index: index, isIndex: true})
+		default:
+			return nil, fmt.Errorf("invalid JSON path %q: unexpected %q", expr, rest[0])
+		}
+	}
+	return path, nil
+}
+
+// unquoteKey strips matching single or double quotes from a bracketed key
+func unquoteKey(s string) (string, bool) {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1], true
+	}
+	return "", false
+}
+
+// String returns the expression the path was parsed from
+func (p *JSONPath) String() string {
+	return p.expr
+}
+
+// Extract returns the value at the path in a JSON payload, false if the payload isn't
+// JSON or the path is absent. Non-empty strings are returned unquoted, other values as
+// JSON so the result is never empty
+func (p *JSONPath) Extract(data []byte) (string, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return "", false
+	}
+
+	for _, step := range p.steps {
+		switch current := value.(type) {
+		case map[string]any:
+			next, ok := current[step.key]
+			if step.isIndex || !ok {
+				return "", false
+			}
+			value = next
+		case []any:
+			if !step.isIndex || step.index >= len(current) {
+				return "", false
+			}
+			value = current[step.index]
+		default:
+			return "", false
+		}
+	}
+
+	if s, ok := value.(string); ok && s != "" {
+		return s, true
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+	return string(encoded), true
+}
@@ -4,8 +4,11 @@
 package monitor
 
 import (
+	"encoding/json"
+	"hash/fnv"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/nats-io/nats.go"
 )
@@ -13,26 +16,62 @@ import (
 type Message struct {
 	Subject   string
 	Data      []byte
-	Timestamp time.Time
+	Timestamp time.Time // When the stream stored the message, or when it was received if not from a stream
+	Sequence  uint64    // Stream sequence, 0 if not from a JetStream stream
 	Headers   nats.Header
+	Extracted string // Value at the viewer's extract path, empty if unset or absent
+	Duplicate bool   // Payload and Nats-Msg-Id match an earlier stored message, if detecting duplicates
+	JSONValid *bool  // Whether the payload parses as JSON, nil if not validating or the payload is empty or binary
+	hash      uint64
 }
 
+// MessageStore keeps the most recent messages in a ring buffer
 type MessageStore struct {
 	mu       sync.RWMutex
-	messages []Message
-	maxSize  int
+	messages []Message // Fixed size ring, slots past count are empty
+	head     int       // Index of the oldest message
+	count    int
+	seen     map[uint64]int // Stored messages per hash, nil unless detecting duplicates
+	validate bool           // Check each payload is valid JSON
+}
+
+// messageHash hashes a message's payload and Nats-Msg-Id header for duplicate detection
+func messageHash(msg *Message) uint64 {
+	h := fnv.New64a()
+	h.Write(msg.Data)
+	h.Write([]byte{0})
+	h.Write([]byte(msg.Headers.Get(nats.MsgIdHdr)))
+	return h.Sum64()
+}
+
+// jsonValidity checks whether a payload is valid JSON, nil for empty or binary payloads
+// since they were never meant to be JSON
+func jsonValidity(data []byte) *bool {
+	if len(data) == 0 || !utf8.Valid(data) {
+		return nil
+	}
+	valid := json.Valid(data)
+	return &valid
 }
 
 // Creates a new Message Store
 func NewMessageStore(maxSize int) *MessageStore {
+	if maxSize < 1 {
+		maxSize = 1
+	}
 	return &MessageStore{
-		messages: make([]Message, 0, maxSize),
-		maxSize:  maxSize,
+		messages: make([]Message, maxSize),
 	}
 }
 
-// Store adds a message to the store, removing oldest if at capacity
-func (m *MessageStore) Store(natsMsg *nats.Msg) {
+// Store adds a message to the store with its extracted value, replacing the oldest if at capacity
+func (m *MessageStore) Store(natsMsg *nats.Msg, extracted string) {
+	m.StoreAt(natsMsg, extracted, time.Now(), 0)
+}
+
+// StoreAt adds a message received at the given time, such as when it was stored in a stream,
+// along with its stream sequence, 0 if it's not from a stream
+func (m *MessageStore) StoreAt(natsMsg *nats.Msg, extracted string, receivedAt time.Time, sequence uint64) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -40,16 +79,31 @@ func (m *MessageStore) Store(natsMsg *nats.Msg) {
 	message := Message{
 		Subject:   natsMsg.Subject,
 		Data:      natsMsg.Data,
-		Timestamp: time.Now(),
+		Timestamp: receivedAt,
+		Sequence:  sequence,
 		Headers:   natsMsg.Header,
+		Extracted: extracted,
 	}
 
-	// If at capacity, remove oldest (shift left)
-	if len(m.messages) >= m.maxSize {
-		m.messages = m.messages[1:]
+	if m.validate {
+		message.JSONValid = jsonValidity(message.Data)
+	}
+	if m.seen != nil {
+		message.hash = messageHash(&message)
+		message.Duplicate = m.seen[message.hash] > 0
+		m.seen[message.hash]++
+	}
+
+	// If at capacity, overwrite the oldest so it can be garbage collected
+	if m.count == len(m.messages) {
+		m.forget(&m.messages[m.head])
+		m.messages[m.head] = message
+		m.head = (m.head + 1) % len(m.messages)
+		return
 	}
 
-	m.messages = append(m.messages, message)
+	m.messages[(m.head+m.count)%len(m.messages)] = message
+	m.count++
 }
 
 // Clear removes all messages from the store
@@ -57,16 +111,104 @@ func (m *MessageStore) Clear() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.messages = make([]Message, 0, m.maxSize)
+	clear(m.messages)
+	m.head = 0
+	m.count = 0
+	if m.seen != nil {
+		clear(m.seen)
+	}
+}
+
+// SetDetectDuplicates turns duplicate detection on or off. Turning it on checks the
+// messages already stored, turning it off clears their duplicate flags
+func (m *MessageStore) SetDetectDuplicates(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if enabled == (m.seen != nil) {
+		return
+	}
+
+	m.seen = nil
+	if enabled {
+		m.seen = make(map[uint64]int)
+	}
+	for i := range m.count {
+		msg := &m.messages[(m.head+i)%len(m.messages)]
+		msg.Duplicate = false
+		if enabled {
+			msg.hash = messageHash(msg)
+			msg.Duplicate = m.seen[msg.hash] > 0
+			m.seen[msg.hash]++
+		}
+	}
+}
+
+// DetectingDuplicates reports whether duplicate detection is on
+func (m *MessageStore) DetectingDuplicates() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.seen != nil
+}
+
+// SetValidateJSON turns JSON validation on or off, checking or clearing the messages
+// already stored to match
+func (m *MessageStore) SetValidateJSON(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if enabled == m.validate {
+		return
+	}
+
+	m.validate = enabled
+	for i := range m.count {
+		msg := &m.messages[(m.head+i)%len(m.messages)]
+		msg.JSONValid = nil
+		if enabled {
+			msg.JSONValid = jsonValidity(msg.Data)
+		}
+	}
 }
 
-// All returns a copy of all messages
+// ValidatingJSON reports whether JSON validation is on
+func (m *MessageStore) ValidatingJSON() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.validate
+}
+
+// forget removes a message that's being overwritten from the duplicate counts
+func (m *MessageStore) forget(msg *Message) {
+	if m.seen == nil {
+		return
+	}
+	if m.seen[msg.hash]--; m.seen[msg.hash] <= 0 {
+		delete(m.seen, msg.hash)
+	}
+}
+
+// Each calls fn on every stored message in place, oldest first
+func (m *MessageStore) Each(fn func(*Message)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.count {
+		fn(&m.messages[(m.head+i)%len(m.messages)])
+	}
+}
+
+// All returns a copy of all messages, oldest first
 func (m *MessageStore) All() []Message {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	result := make([]Message, len(m.messages))
-	copy(result, m.messages)
+	result := make([]Message, m.count)
+	for i := range result {
+		result[i] = m.messages[(m.head+i)%len(m.messages)]
+	}
 	return result
 }
 
@@ -75,5 +217,30 @@ func (m *MessageStore) Count() int {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	return m.count
+}
+
+// Capacity returns the number of messages the store holds before evicting the oldest
+func (m *MessageStore) Capacity() int {
 	return len(m.messages)
 }
+
+// SizeBytes estimates the memory held by the stored messages from their subjects,
+// payloads, headers and extracted values
+func (m *MessageStore) SizeBytes() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	size := 0
+	for i := range m.count {
+		msg := &m.messages[(m.head+i)%len(m.messages)]
+		size += len(msg.Subject) + len(msg.Data) + len(msg.Extracted)
+		for key, values := range msg.Headers {
+			size += len(key)
+			for _, value := range values {
+				size += len(value)
+			}
+		}
+	}
+	return size
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package monitor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+)
+
+// storeMessages stores a message per subject, in order
+func storeMessages(store *MessageStore, subjects ...string) {
+	for _, subject := range subjects {
+		store.Store(&nats.Msg{Subject: subject, Data: []byte("data " + subject)}, "")
+	}
+}
+
+// subjectsOf returns the subjects of messages, in order
+func subjectsOf(messages []Message) string {
+	var subjects []string
+	for _, msg := range messages {
+		subjects = append(subjects, msg.Subject)
+	}
+	return fmt.Sprint(subjects)
+}
+
+func TestMessageStoreWraparound(t *testing.T) {
+	store := NewMessageStore(3)
+	storeMessages(store, "a", "b")
+	if got := subjectsOf(store.All()); got != "[a b]" {
+		t.Errorf("before wrapping All() = %s, want [a b]", got)
+	}
+
+	storeMessages(store, "c", "d", "e")
+	if got := subjectsOf(store.All()); got != "[c d e]" {
+		t.Errorf("after wrapping All() = %s, want [c d e]", got)
+	}
+	if count := store.Count(); count != 3 {
+		t.Errorf("Count() = %d, want 3", count)
+	}
+
+	var each []Message
+	store.Each(func(msg *Message) { each = append(each, *msg) })
+	if got := subjectsOf(each); got != "[c d e]" {
+		t.Errorf("Each() visited %s, want [c d e]", got)
+	}
+}
+
+func TestViewerGetMessagesOldestFirst(t *testing.T) {
+	viewer := NewViewer(nil, 2)
+	storeMessages(viewer.messages, "a", "b", "c")
+
+	if got := subjectsOf(viewer.GetMessages()); got != "[b c]" {
+		t.Errorf("GetMessages() = %s, want [b c]", got)
+	}
+}
+
+func TestMessageStoreReleasesEvicted(t *testing.T) {
+	store := NewMessageStore(2)
+	storeMessages(store, "a", "b", "c", "d", "e")
+
+	for i, msg := range store.messages {
+		if msg.Subject == "a" || msg.Subject == "b" || msg.Subject == "c" {
+			t.Errorf("slot %d still holds evicted message %s", i, msg.Subject)
+		}
+	}
+
+	store.Clear()
+	for i, msg := range store.messages {
+		if msg.Data != nil || msg.Subject != "" {
+			t.Errorf("slot %d still holds %s after Clear", i, msg.Subject)
+		}
+	}
+	if all := store.All(); len(all) != 0 {
+		t.Errorf("All() after Clear = %s, want none", subjectsOf(all))
+	}
+}
+
+func TestMessageStoreDuplicatesAfterEviction(t *testing.T) {
+	store := NewMessageStore(2)
+	store.SetDetectDuplicates(true)
+	storeMessages(store, "a", "a", "b", "b")
+
+	// The first pair of "a" messages were evicted, so "a" is new again
+	storeMessages(store, "a")
+	all := store.All()
+	if got := all[len(all)-1]; got.Subject != "a" || got.Duplicate {
+		t.Errorf("latest message = %s duplicate %v, want a not duplicate", got.Subject, got.Duplicate)
+	}
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package monitor
+
+import (
+	"sync"
+	"time"
+)
+
+// RateCounter tracks events per second over a rolling window using one bucket per second
+type RateCounter struct {
+	mu      sync.Mutex
+	window  int // Seconds averaged by Rate, at most len(counts)
+	counts  []int64
+	seconds []int64 // Unix second each bucket was last written for
+}
+
+// NewRateCounter creates a rate counter averaging over windowSeconds and
+// keeping per-second counts for the last historySeconds
+func NewRateCounter(windowSeconds int, historySeconds int) *RateCounter {
+	if windowSeconds < 1 {
+		windowSeconds = 1
+	}
+	buckets := max(windowSeconds, historySeconds)
+	return &RateCounter{
+		window:  windowSeconds,
+		counts:  make([]int64, buckets),
+		seconds: make([]int64, buckets),
+	}
+}
+
+// Add records a single event at the given time
+func (r *RateCounter) Add(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sec := now.Unix()
+	i := int(sec % int64(len(r.counts)))
+	if r.seconds[i] != sec {
+		// Bucket holds a stale second, start it over
+		r.seconds[i] = sec
+		r.counts[i] = 0
+	}
+	r.counts[i]++
+}
+
+// Rate returns the average events per second over the window ending at now,
+// decaying to zero once no events have been recorded within the window
+func (r *RateCounter) Rate(now time.Time) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	window := int64(r.window)
+	oldest := now.Unix() - window + 1
+
+	var total int64
+	for i, sec := range r.seconds {
+		if sec >= oldest {
+			total += r.counts[i]
+		}
+	}
+	return float64(total) / float64(window)
+}
+
+// History returns the event count for each second in the last n seconds ending at now,
+// oldest first. n is capped at the number of seconds the counter keeps
+func (r *RateCounter) History(now time.Time, n int) []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n = min(n, len(r.counts))
+	history := make([]int, n)
+	for i := range history {
+		sec := now.Unix() - int64(n-1-i)
+		bucket := int(sec % int64(len(r.counts)))
+		if r.seconds[bucket] == sec {
+			history[i] = int(r.counts[bucket])
+		}
+	}
+	return history
+}
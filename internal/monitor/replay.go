@@ -0,0 +1,274 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eallender/nats-ls/internal/logger"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	// replayBatchSize is the number of messages fetched per request while replaying
+	replayBatchSize = 256
+	// replayFetchWait bounds each fetch, reached only if no messages match
+	replayFetchWait = time.Second
+	// replayProbeThreshold is how long the server keeps a consumer counting messages for
+	// a "last N" replay if deleting it fails
+	replayProbeThreshold = 10 * time.Second
+)
+
+// ReplayStart is where a replay begins in a stream
+type ReplayStart struct {
+	Last  int       // Replay only the last Last messages, 0 for no limit
+	Since time.Time // Replay messages stored at or after Since, zero for all
+}
+
+// ParseReplayStart parses "all", "last <n>", or "since <duration|RFC 3339 time>"
+func ParseReplayStart(s string) (ReplayStart, error) {
+	mode, arg, _ := strings.Cut(strings.TrimSpace(s), " ")
+	arg = strings.TrimSpace(arg)
+
+	switch mode {
+	case "all":
+		return ReplayStart{}, nil
+	case "last":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 {
+			return ReplayStart{}, fmt.Errorf("last takes a positive number of messages")
+		}
+		return ReplayStart{Last: n}, nil
+	case "since":
+		if d, err := time.ParseDuration(arg); err == nil && d > 0 {
+			return ReplayStart{Since: time.Now().Add(-d)}, nil
+		}
+		if t, err := time.Parse(time.RFC3339, arg); err == nil {
+			return ReplayStart{Since: t}, nil
+		}
+		return ReplayStart{}, fmt.Errorf("since takes a duration like 10m or an RFC 3339 time")
+	}
+	return ReplayStart{}, fmt.Errorf("unknown replay start %q", mode)
+}
+
+// String describes where the replay begins
+func (s ReplayStart) String() string {
+	switch {
+	case s.Last > 0:
+		return fmt.Sprintf("last %d", s.Last)
+	case !s.Since.IsZero():
+		return "since " + s.Since.Format(time.DateTime)
+	}
+	return "all"
+}
+
+// StreamForSubject returns the name of the stream storing subject
+func (j *JetStream) StreamForSubject(ctx context.Context, subject string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, jetStreamRequestTimeout)
+	defer cancel()
+
+	return j.js.StreamNameBySubject(ctx, subject)
+}
+
+// IsStreamNotFound reports whether err means no stream stores a subject
+func IsStreamNotFound(err error) bool {
+	return errors.Is(err, jetstream.ErrStreamNotFound)
+}
+
+// Replay replaces the viewer's messages with the watched subject's messages stored in a stream,
+// read through an ephemeral ordered consumer and stored with the time they were stored. Live
+// messages arriving meanwhile are added after them, and the replay stops at the subject's last
+// message when it began so they aren't read twice. It stops early if the viewer switches
+// subjects and returns the number stored
+func (v *Viewer) Replay(ctx context.Context, js *JetStream, stream, subject string, start ReplayStart) (int, error) {
+	generation := v.generation.Load()
+	v.holdLive(generation)
+
+	// Live messages received before the last sequence is known may be in the stream up to it,
+	// remember the message there to recognize them
+	var stoppedAt *nats.Msg
+	window := 0
+	defer func() { v.releaseLive(generation, stoppedAt, window) }()
+
+	until, err := js.lastSequence(ctx, stream, subject)
+	if err != nil || until == 0 {
+		return 0, err
+	}
+	window = v.liveReceived()
+
+	config := jetstream.OrderedConsumerConfig{
+		FilterSubjects: []string{subject},
+		DeliverPolicy:  jetstream.DeliverAllPolicy,
+	}
+	if !start.Since.IsZero() {
+		config.DeliverPolicy = jetstream.DeliverByStartTimePolicy
+		config.OptStartTime = &start.Since
+	}
+	pending := uint64(replayBatchSize) // Unknown until the first message says how many follow
+	if start.Last > 0 {
+		seq, count, err := js.lastStartSequence(ctx, stream, subject, start.Last, until)
+		if err != nil {
+			return 0, err
+		}
+		config.DeliverPolicy = jetstream.DeliverByStartSequencePolicy
+		config.OptStartSeq = seq
+		pending = count
+	}
+
+	createCtx, cancel := context.WithTimeout(ctx, jetStreamRequestTimeout)
+	consumer, err := js.js.OrderedConsumer(createCtx, stream, config)
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+	logger.Log.Info("Replaying stream", "stream", stream, "subject", subject, "start", start.String())
+
+	// A "last N" replay starts a few messages early, so it keeps the last N it reads and
+	// stores them at the end
+	var last []replayedMessage
+	stored := 0
+	store := func(msg replayedMessage) {
+		if v.accept(msg.msg) {
+			v.messages.StoreAt(msg.msg, v.extractFrom(msg.msg.Data), msg.meta.Timestamp, msg.meta.Sequence.Stream)
+			stored++
+		}
+	}
+	finish := func() {
+		for _, msg := range last {
+			store(msg)
+		}
+		logger.Log.Info("Replayed stream", "stream", stream, "subject", subject, "count", stored)
+	}
+
+	for {
+		// Ask for no more than remain, so the last fetch doesn't wait out its timeout
+		batch, err := consumer.Fetch(int(min(pending, replayBatchSize)), jetstream.FetchMaxWait(replayFetchWait))
+		if err != nil {
+			return stored, err
+		}
+
+		fetched := 0
+		done := false
+		for msg := range batch.Messages() {
+			fetched++
+			if v.generation.Load() != generation {
+				return stored, nil
+			}
+
+			meta, err := msg.Metadata()
+			if err != nil {
+				continue
+			}
+			pending = meta.NumPending
+			if meta.Sequence.Stream > until {
+				done = true
+				break
+			}
+
+			replayed := replayedMessage{msg: &nats.Msg{Subject: msg.Subject(), Data: msg.Data(), Header: msg.Headers()}, meta: meta}
+			if start.Last > 0 {
+				if len(last) == start.Last {
+					last = last[1:]
+				}
+				last = append(last, replayed)
+			} else {
+				store(replayed)
+			}
+			if meta.Sequence.Stream == until {
+				stoppedAt = replayed.msg
+				done = true
+				break
+			}
+		}
+		if err := batch.Error(); err != nil && !errors.Is(err, nats.ErrTimeout) {
+			return stored, err
+		}
+		if done || pending == 0 || fetched == 0 || ctx.Err() != nil {
+			finish()
+			return stored, ctx.Err()
+		}
+	}
+}
+
+// replayedMessage is a message read from a stream along with where and when it was stored
+type replayedMessage struct {
+	msg  *nats.Msg
+	meta *jetstream.MsgMetadata
+}
+
+// lastSequence returns the stream sequence of the last message stored on subject, 0 if none
+func (j *JetStream) lastSequence(ctx context.Context, stream, subject string) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, jetStreamRequestTimeout)
+	defer cancel()
+
+	s, err := j.js.Stream(ctx, stream)
+	if err != nil {
+		return 0, err
+	}
+	msg, err := s.GetLastMsgForSubject(ctx, subject)
+	if errors.Is(err, jetstream.ErrMsgNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return msg.Sequence, nil
+}
+
+// lastStartSequence returns a stream sequence at or before the last n messages on subject,
+// the last of which is stored at sequence last, and how many messages on subject are stored
+// from it, so a "last N" replay reads about n messages instead of the whole stream. Starting
+// from the last message it doubles the window until it holds n matching messages, counting
+// them with short-lived consumers rather than fetching them
+func (j *JetStream) lastStartSequence(ctx context.Context, stream, subject string, n int, last uint64) (uint64, uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, jetStreamRequestTimeout)
+	defer cancel()
+
+	s, err := j.js.Stream(ctx, stream)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	first := s.CachedInfo().State.FirstSeq
+	for window := uint64(n); ; window *= 2 {
+		start := first
+		if last+1 > first+window {
+			start = last + 1 - window
+		}
+
+		count, err := countMessagesFrom(ctx, s, subject, start)
+		if err != nil {
+			return 0, 0, err
+		}
+		if count >= uint64(n) || start == first {
+			return start, count, nil
+		}
+	}
+}
+
+// countMessagesFrom returns how many of a stream's messages on subject are stored at or after seq
+func countMessagesFrom(ctx context.Context, s jetstream.Stream, subject string, seq uint64) (uint64, error) {
+	consumer, err := s.CreateConsumer(ctx, jetstream.ConsumerConfig{
+		FilterSubject:     subject,
+		DeliverPolicy:     jetstream.DeliverByStartSequencePolicy,
+		OptStartSeq:       seq,
+		AckPolicy:         jetstream.AckNonePolicy,
+		InactiveThreshold: replayProbeThreshold,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	info := consumer.CachedInfo()
+	if err := s.DeleteConsumer(ctx, info.Name); err != nil {
+		logger.Log.Debug("Failed to delete replay probe consumer", "stream", s.CachedInfo().Config.Name, "consumer", info.Name, "error", err)
+	}
+	return info.NumPending, nil
+}
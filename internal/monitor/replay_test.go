@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+func TestReplayHoldsLiveMessages(t *testing.T) {
+	viewer := NewViewer(nil, 10)
+	viewer.generation.Store(1)
+	viewer.receive(&nats.Msg{Subject: "before"})
+
+	viewer.holdLive(1)
+	viewer.receive(&nats.Msg{Subject: "live"})
+	if count := viewer.GetMessageCount(); count != 0 {
+		t.Fatalf("%d messages stored while replaying, want 0", count)
+	}
+
+	viewer.messages.StoreAt(&nats.Msg{Subject: "replayed"}, "", time.Now(), 1)
+	viewer.releaseLive(1, nil, 0)
+	viewer.receive(&nats.Msg{Subject: "after"})
+
+	if got := subjectsOf(viewer.GetMessages()); got != "[replayed live after]" {
+		t.Errorf("GetMessages() = %s, want [replayed live after]", got)
+	}
+}
+
+func TestReplayDropsHeldAfterSubjectChange(t *testing.T) {
+	viewer := NewViewer(nil, 10)
+	viewer.generation.Store(1)
+
+	viewer.holdLive(1)
+	viewer.receive(&nats.Msg{Subject: "old"})
+	// Watching another subject, its messages aren't held by the old replay
+	viewer.generation.Store(2)
+	viewer.receive(&nats.Msg{Subject: "new"})
+	viewer.releaseLive(1, nil, 0)
+
+	if got := subjectsOf(viewer.GetMessages()); got != "[new]" {
+		t.Errorf("GetMessages() = %s, want [new]", got)
+	}
+}
+
+func TestReplaySkipsHeldMessagesInStream(t *testing.T) {
+	viewer := NewViewer(nil, 10)
+	viewer.generation.Store(1)
+
+	viewer.holdLive(1)
+	// Published while the replay looked up where to stop, so both held and in the stream
+	viewer.receive(&nats.Msg{Subject: "orders", Data: []byte("1")})
+	viewer.receive(&nats.Msg{Subject: "orders", Data: []byte("2")})
+	viewer.receive(&nats.Msg{Subject: "orders", Data: []byte("3")})
+
+	stoppedAt := &nats.Msg{Subject: "orders", Data: []byte("2")}
+	viewer.messages.StoreAt(stoppedAt, "", time.Now(), 2)
+	viewer.releaseLive(1, stoppedAt, 2)
+
+	var payloads []string
+	for _, msg := range viewer.GetMessages() {
+		payloads = append(payloads, string(msg.Data))
+	}
+	if got := strings.Join(payloads, ","); got != "2,3" {
+		t.Errorf("stored payloads %s, want 2,3", got)
+	}
+}
@@ -4,9 +4,14 @@
 package monitor
 
 import (
+	"container/list"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/eallender/nats-ls/internal/logger"
+	"github.com/nats-io/nats.go"
 )
 
 type SubjectInfo struct {
@@ -14,27 +19,224 @@ type SubjectInfo struct {
 	FirstSeen    time.Time
 	LastSeen     atomic.Value
 	MessageCount atomic.Int64
+	TotalBytes   atomic.Int64
+	HasReplyTo   atomic.Bool // Set once a message with a reply subject is seen
+	contentType  atomic.Value
+	preview      atomic.Value // Start of the latest payload, if the store keeps previews
+	rate         *RateCounter
+}
+
+// Preview returns the start of the subject's latest payload, empty if previews aren't kept
+func (i *SubjectInfo) Preview() string {
+	preview, _ := i.preview.Load().(string)
+	return preview
+}
+
+// ContentType returns the guessed format of the subject's payloads
+func (i *SubjectInfo) ContentType() ContentType {
+	contentType, _ := i.contentType.Load().(ContentType)
+	return contentType
+}
+
+// Rate returns the subject's recent message rate in messages per second
+func (i *SubjectInfo) Rate() float64 {
+	return i.rate.Rate(time.Now())
+}
+
+// History returns the subject's message count for each of the last seconds seconds, oldest first
+func (i *SubjectInfo) History(seconds int) []int {
+	return i.rate.History(time.Now(), seconds)
+}
+
+// SubjectSnapshot is a point-in-time copy of a subject's stats
+type SubjectSnapshot struct {
+	Name         string
+	FirstSeen    time.Time
+	LastSeen     time.Time
+	MessageCount int64
+	TotalBytes   int64
+	Rate         float64
+	HasReplyTo   bool
+	ContentType  ContentType
+	Preview      string
+}
+
+// Snapshot copies the subject's current stats
+func (i *SubjectInfo) Snapshot() SubjectSnapshot {
+	return SubjectSnapshot{
+		Name:         i.Name,
+		FirstSeen:    i.FirstSeen,
+		LastSeen:     i.LastSeen.Load().(time.Time),
+		MessageCount: i.MessageCount.Load(),
+		TotalBytes:   i.TotalBytes.Load(),
+		Rate:         i.Rate(),
+		HasReplyTo:   i.HasReplyTo.Load(),
+		ContentType:  i.ContentType(),
+		Preview:      i.Preview(),
+	}
 }
 
 type SubjectStore struct {
-	subjects sync.Map
+	subjects          sync.Map
+	rateWindowSeconds int
+	historySeconds    int // Per-second message counts kept for each subject
+	maxSubjects       int // Evict least recently seen subjects beyond this count, 0 for unlimited
+	previewBytes      int // Bytes of the latest payload kept per subject, 0 keeps none
+
+	// Recency order for eviction, most recently seen at the front
+	mu       sync.Mutex
+	recency  *list.List
+	elements map[string]*list.Element
+	evicted  atomic.Int64
+
+	total atomic.Int64 // Messages recorded across all subjects, including evicted ones
+
+	// Record holds a read lock while updating a subject and PurgeStale a write lock while
+	// checking and deleting, so a message is never counted on a subject that's being purged
+	purgeMu sync.RWMutex
+}
+
+// NewSubjectStore creates a subject store averaging message rates over rateWindowSeconds,
+// keeping historySeconds of per-second counts, holding at most maxSubjects subjects,
+// or unlimited if maxSubjects is 0, and keeping previewBytes of each latest payload
+func NewSubjectStore(rateWindowSeconds int, historySeconds int, maxSubjects int, previewBytes int) *SubjectStore {
+	return &SubjectStore{
+		rateWindowSeconds: rateWindowSeconds,
+		historySeconds:    historySeconds,
+		maxSubjects:       maxSubjects,
+		previewBytes:      previewBytes,
+		recency:           list.New(),
+		elements:          make(map[string]*list.Element),
+	}
 }
 
-func (s *SubjectStore) Record(subject string) (isNew bool) {
+// Record tracks a message on its subject
+func (s *SubjectStore) Record(msg *nats.Msg) (isNew bool) {
+	s.purgeMu.RLock()
+	defer s.purgeMu.RUnlock()
+
 	now := time.Now()
+	subject := msg.Subject
+	s.total.Add(1)
 
-	actual, loaded := s.subjects.LoadOrStore(subject, &SubjectInfo{
-		Name:      subject,
-		FirstSeen: now,
-	})
+	// Only build a new subject on a miss, its rate counter is too large to allocate per message
+	actual, loaded := s.subjects.Load(subject)
+	if !loaded {
+		candidate := &SubjectInfo{
+			Name:      subject,
+			FirstSeen: now,
+			rate:      NewRateCounter(s.rateWindowSeconds, s.historySeconds),
+		}
+		// Set LastSeen before the subject is visible, the stale sweeper and snapshots read it
+		// from other goroutines as soon as it's stored
+		candidate.LastSeen.Store(now)
+		actual, loaded = s.subjects.LoadOrStore(subject, candidate)
+	}
 
 	info := actual.(*SubjectInfo)
 	info.LastSeen.Store(now)
-	info.MessageCount.Add(1)
+	count := info.MessageCount.Add(1)
+	info.TotalBytes.Add(int64(len(msg.Data)))
+	info.rate.Add(now)
+	if msg.Reply != "" {
+		info.HasReplyTo.Store(true)
+	}
+
+	// Sample the first payload and resample occasionally, keeping the callback cheap
+	if count%contentTypeResampleInterval == 1 {
+		info.contentType.Store(GuessContentType(msg.Data))
+	}
+
+	// Copy only the start of the payload so previews stay small
+	if s.previewBytes > 0 {
+		info.preview.Store(string(msg.Data[:min(len(msg.Data), s.previewBytes)]))
+	}
+
+	if s.maxSubjects > 0 {
+		s.touch(subject)
+	}
 
 	return !loaded
 }
 
+// touch marks a subject as the most recently seen and evicts the least
+// recently seen subjects once the store holds more than maxSubjects
+func (s *SubjectStore) touch(subject string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.elements[subject]; ok {
+		s.recency.MoveToFront(element)
+		return
+	}
+	s.elements[subject] = s.recency.PushFront(subject)
+
+	for s.recency.Len() > s.maxSubjects {
+		oldest := s.recency.Back()
+		name := s.recency.Remove(oldest).(string)
+		delete(s.elements, name)
+		s.subjects.Delete(name)
+
+		evicted := s.evicted.Add(1)
+		logger.Log.Debug("Evicted least recently seen subject", "subject", name, "max_subjects", s.maxSubjects, "evicted_total", evicted)
+	}
+}
+
+// PurgeStale deletes subjects last seen before cutoff and returns how many were removed
+func (s *SubjectStore) PurgeStale(cutoff time.Time) int {
+	s.purgeMu.Lock()
+	defer s.purgeMu.Unlock()
+
+	purged := 0
+	s.subjects.Range(func(key, value any) bool {
+		info := value.(*SubjectInfo)
+		if !info.LastSeen.Load().(time.Time).Before(cutoff) {
+			return true
+		}
+
+		s.subjects.Delete(key)
+		if s.maxSubjects > 0 {
+			s.mu.Lock()
+			if element, ok := s.elements[info.Name]; ok {
+				s.recency.Remove(element)
+				delete(s.elements, info.Name)
+			}
+			s.mu.Unlock()
+		}
+		purged++
+		return true
+	})
+	return purged
+}
+
+// Total returns the number of messages recorded since the store was created
+func (s *SubjectStore) Total() int64 {
+	return s.total.Load()
+}
+
+// subjectOverheadBytes approximates the fixed memory of a tracked subject: its SubjectInfo,
+// rate counter and map and recency list entries, not counting its name, preview or buckets
+const subjectOverheadBytes = 256
+
+// SizeBytes estimates the memory held by the tracked subjects
+func (s *SubjectStore) SizeBytes() int {
+	// Each rate bucket holds a count and the second it was written for
+	bucketBytes := max(s.rateWindowSeconds, s.historySeconds, 1) * 16
+
+	size := 0
+	s.subjects.Range(func(_, value any) bool {
+		info := value.(*SubjectInfo)
+		size += subjectOverheadBytes + bucketBytes + len(info.Name) + len(info.Preview())
+		return true
+	})
+	return size
+}
+
+// Evicted returns the number of subjects evicted to stay within maxSubjects
+func (s *SubjectStore) Evicted() int64 {
+	return s.evicted.Load()
+}
+
 func (s *SubjectStore) All() []*SubjectInfo {
 	var result []*SubjectInfo
 	s.subjects.Range(func(_, value any) bool {
@@ -51,3 +253,24 @@ func (s *SubjectStore) Get(subject string) (*SubjectInfo, bool) {
 	}
 	return val.(*SubjectInfo), true
 }
+
+// MatchSubject reports whether subject matches the NATS wildcard pattern with tokens
+// separated by sep, where "*" matches a single token and ">" matches one or more trailing tokens
+func MatchSubject(pattern, subject, sep string) bool {
+	patternTokens := strings.Split(pattern, sep)
+	subjectTokens := strings.Split(subject, sep)
+
+	for i, token := range patternTokens {
+		if token == ">" {
+			return i == len(patternTokens)-1 && len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) {
+			return false
+		}
+		if token != "*" && token != subjectTokens[i] {
+			return false
+		}
+	}
+
+	return len(patternTokens) == len(subjectTokens)
+}
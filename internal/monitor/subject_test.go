@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package monitor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+func TestPurgeStale(t *testing.T) {
+	store := NewSubjectStore(10, 10, 0, 0)
+	store.Record(&nats.Msg{Subject: "orders.old"})
+	cutoff := time.Now()
+	time.Sleep(time.Millisecond)
+	store.Record(&nats.Msg{Subject: "orders.new"})
+
+	if purged := store.PurgeStale(cutoff.Add(time.Nanosecond)); purged != 1 {
+		t.Fatalf("PurgeStale() = %d, want 1", purged)
+	}
+	if _, ok := store.Get("orders.old"); ok {
+		t.Error("orders.old was not purged")
+	}
+	if _, ok := store.Get("orders.new"); !ok {
+		t.Error("orders.new was purged")
+	}
+}
+
+func TestMatchSubject(t *testing.T) {
+	tests := []struct {
+		pattern string
+		subject string
+		sep     string
+		want    bool
+	}{
+		{"orders.*", "orders.new", ".", true},
+		{"orders.>", "orders.new.us", ".", true},
+		{"orders.>", "orders", ".", false},
+		{"orders.*", "orders.new.us", ".", false},
+		{"orders/*", "orders/new", "/", true},
+		{"orders/>", "orders/new/us", "/", true},
+		{"orders/*", "orders/new/us", "/", false},
+		{"orders.*", "orders/new", "/", false},
+		{"*", "orders.new", "/", true},
+	}
+	for _, tt := range tests {
+		if got := MatchSubject(tt.pattern, tt.subject, tt.sep); got != tt.want {
+			t.Errorf("MatchSubject(%q, %q, %q) = %v, want %v", tt.pattern, tt.subject, tt.sep, got, tt.want)
+		}
+	}
+}
+
+func TestSubjectStoreTotal(t *testing.T) {
+	store := NewSubjectStore(10, 10, 1, 0)
+	store.Record(&nats.Msg{Subject: "orders.new"})
+	store.Record(&nats.Msg{Subject: "orders.new"})
+	store.Record(&nats.Msg{Subject: "orders.paid"})
+
+	// Messages on evicted subjects still count towards the total
+	if total := store.Total(); total != 3 {
+		t.Errorf("Total() = %d, want 3", total)
+	}
+	if evicted := store.Evicted(); evicted != 1 {
+		t.Errorf("Evicted() = %d, want 1", evicted)
+	}
+
+	d := &Discovery{store: store}
+	if total := d.TotalMessages(); total != 3 {
+		t.Errorf("TotalMessages() = %d, want 3", total)
+	}
+}
+
+func TestSubjectStoreConcurrentRecord(t *testing.T) {
+	store := NewSubjectStore(10, 10, 0, 0)
+	d := &Discovery{store: store}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 500 {
+				store.Record(&nats.Msg{Subject: "orders.new", Data: []byte("ab")})
+			}
+		}()
+	}
+	wg.Wait()
+
+	snapshot := d.Snapshot()
+	if len(snapshot) != 1 {
+		t.Fatalf("Snapshot() has %d subjects, want 1", len(snapshot))
+	}
+	if got := snapshot[0]; got.MessageCount != 4000 || got.TotalBytes != 8000 {
+		t.Errorf("orders.new has %d messages and %d bytes, want 4000 and 8000", got.MessageCount, got.TotalBytes)
+	}
+}
+
+func TestRecordKnownSubjectAllocations(t *testing.T) {
+	store := NewSubjectStore(10, 60, 0, 0)
+	msg := &nats.Msg{Subject: "orders.new", Data: []byte("{}")}
+	store.Record(msg)
+
+	// A known subject reuses its rate counter instead of building a new one per message
+	allocs := testing.AllocsPerRun(100, func() { store.Record(msg) })
+	if allocs > 1 {
+		t.Errorf("Record() on a known subject made %.0f allocations, want at most 1", allocs)
+	}
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package monitor
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// SubjectTree is a node in the hierarchy of subject tokens
+type SubjectTree struct {
+	Name            string         `json:"name"`
+	Subject         string         `json:"subject,omitempty"` // Full subject, set if messages were seen on this exact subject
+	MessageCount    int64          `json:"message_count"`     // Messages on this subject and all descendants
+	DirectCount     int64          `json:"direct_count"`      // Messages on exactly this subject
+	DescendantCount int64          `json:"descendant_count"`  // Messages on subjects below this one
+	Children        []*SubjectTree `json:"children,omitempty"`
+	sep             string         // Separator the root's subjects were split on, joining DOT node ids
+}
+
+// BuildSubjectTree arranges subjects into a tree split on sep with aggregated counts
+func BuildSubjectTree(subjects []SubjectSnapshot, sep string) *SubjectTree {
+	root := &SubjectTree{sep: sep}
+
+	for _, subject := range subjects {
+		node := root
+		node.MessageCount += subject.MessageCount
+		for _, token := range strings.Split(subject.Name, sep) {
+			node.DescendantCount += subject.MessageCount
+			node = node.child(token)
+			node.MessageCount += subject.MessageCount
+		}
+		node.Subject = subject.Name
+		node.DirectCount += subject.MessageCount
+	}
+
+	root.sort()
+	return root
+}
+
+// child returns the child with the given name, creating it if needed
+func (t *SubjectTree) child(name string) *SubjectTree {
+	for _, child := range t.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	child := &SubjectTree{Name: name}
+	t.Children = append(t.Children, child)
+	return child
+}
+
+// sort orders children alphabetically at every level
+func (t *SubjectTree) sort() {
+	sort.Slice(t.Children, func(i, j int) bool {
+		return t.Children[i].Name < t.Children[j].Name
+	})
+	for _, child := range t.Children {
+		child.sort()
+	}
+}
+
+// WriteDOT writes the tree's descendants to w as a Graphviz DOT graph, with a node per
+// token labelled with its message count and an edge from each prefix to its children
+func (t *SubjectTree) WriteDOT(w io.Writer) error {
+	sep := t.sep
+	if sep == "" {
+		sep = "."
+	}
+
+	b := bufio.NewWriter(w)
+	b.WriteString("digraph subjects {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, child := range t.Children {
+		child.writeDOT(b, "", sep)
+	}
+	b.WriteString("}\n")
+	return b.Flush()
+}
+
+// writeDOT writes the node, its edges and its descendants, identifying nodes by their
+// full path joined by sep
+func (t *SubjectTree) writeDOT(b *bufio.Writer, parent, sep string) {
+	id := t.Name
+	if parent != "" {
+		id = parent + sep + t.Name
+	}
+
+	fmt.Fprintf(b, "  \"%s\" [label=\"%s\\n%d msgs\"];\n", dotEscape(id), dotEscape(t.Name), t.MessageCount)
+	if parent != "" {
+		fmt.Fprintf(b, "  \"%s\" -> \"%s\";\n", dotEscape(parent), dotEscape(id))
+	}
+	for _, child := range t.Children {
+		child.writeDOT(b, id, sep)
+	}
+}
+
+// dotEscape escapes a string for use inside a quoted DOT identifier
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
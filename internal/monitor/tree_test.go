@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package monitor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildSubjectTreeSeparator(t *testing.T) {
+	tree := BuildSubjectTree([]SubjectSnapshot{
+		{Name: "orders/new", MessageCount: 2},
+		{Name: "orders/paid.eu", MessageCount: 3},
+	}, "/")
+
+	if len(tree.Children) != 1 || tree.Children[0].Name != "orders" {
+		t.Fatalf("root children = %+v, want just orders", tree.Children)
+	}
+	orders := tree.Children[0]
+	if orders.MessageCount != 5 {
+		t.Errorf("orders MessageCount = %d, want 5", orders.MessageCount)
+	}
+	var names []string
+	for _, child := range orders.Children {
+		names = append(names, child.Name)
+	}
+	if got := strings.Join(names, ","); got != "new,paid.eu" {
+		t.Errorf("orders children = %s, want new,paid.eu", got)
+	}
+
+	var dot strings.Builder
+	if err := tree.WriteDOT(&dot); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(dot.String(), `"orders" -> "orders/paid.eu";`) {
+		t.Errorf("DOT output doesn't join node ids on the separator:\n%s", dot.String())
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	// Subjects arrive in map order, the output must not depend on it
+	tree := BuildSubjectTree([]SubjectSnapshot{
+		{Name: "orders.paid", MessageCount: 3},
+		{Name: `say."hi"`, MessageCount: 1},
+		{Name: "orders.new", MessageCount: 2},
+	}, ".")
+
+	var dot strings.Builder
+	if err := tree.WriteDOT(&dot); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `digraph subjects {
+  rankdir=LR;
+  node [shape=box];
+  "orders" [label="orders\n5 msgs"];
+  "orders.new" [label="new\n2 msgs"];
+  "orders" -> "orders.new";
+  "orders.paid" [label="paid\n3 msgs"];
+  "orders" -> "orders.paid";
+  "say" [label="say\n1 msgs"];
+  "say.\"hi\"" [label="\"hi\"\n1 msgs"];
+  "say" -> "say.\"hi\"";
+}
+`
+	if dot.String() != want {
+		t.Errorf("WriteDOT() =\n%s\nwant\n%s", dot.String(), want)
+	}
+}
@@ -4,7 +4,18 @@
 package monitor
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/eallender/nats-ls/internal/logger"
 	"github.com/nats-io/nats.go"
@@ -15,6 +26,16 @@ type Viewer struct {
 	sub      *nats.Subscription
 	mu       sync.Mutex
 	messages *MessageStore
+	filter   atomic.Pointer[regexp.Regexp] // Only payloads matching the filter are stored, nil stores all
+	extract  atomic.Pointer[JSONPath]      // Path extracted from each payload as it's stored, nil extracts nothing
+	headers  atomic.Pointer[HeaderFilter]  // Only messages carrying the header are stored, nil stores all
+	// generation counts subject changes so a replay can tell the subject it reads is no longer watched
+	generation atomic.Uint64
+
+	// Live messages arriving during a replay are held and stored after it, so history stays in order
+	liveMu  sync.Mutex
+	holdGen uint64      // Generation whose live messages are held, 0 if not replaying
+	held    []*nats.Msg // Held live messages, oldest first, at most the store's capacity
 }
 
 func NewViewer(nc *nats.Conn, maxMessages int) *Viewer {
@@ -37,16 +58,19 @@ func (v *Viewer) Watch(subject string) error {
 		v.sub.Unsubscribe()
 		v.sub = nil
 	}
+	v.generation.Add(1)
+	v.filter.Store(nil)
+	v.extract.Store(nil)
+	v.headers.Store(nil)
+	v.messages.SetDetectDuplicates(false)
+	v.messages.SetValidateJSON(false)
 
 	if subject == "" {
 		return nil
 	}
 
 	var err error
-	v.sub, err = v.nc.Subscribe(subject, func(msg *nats.Msg) {
-		v.messages.Store(msg)
-		logger.Log.Debug("Message received", "subject", msg.Subject, "size", len(msg.Data))
-	})
+	v.sub, err = v.nc.Subscribe(subject, v.receive)
 	if err != nil {
 		return err
 	}
@@ -55,6 +79,174 @@ func (v *Viewer) Watch(subject string) error {
 	return err
 }
 
+// receive stores a live message, or holds it while the watched subject is being replayed
+func (v *Viewer) receive(msg *nats.Msg) {
+	v.liveMu.Lock()
+	defer v.liveMu.Unlock()
+
+	if v.holdGen != 0 && v.holdGen == v.generation.Load() {
+		if len(v.held) == v.messages.Capacity() {
+			v.held = v.held[1:]
+		}
+		v.held = append(v.held, msg)
+		return
+	}
+	v.storeLive(msg)
+}
+
+// storeLive stores a live message if it passes the filters
+func (v *Viewer) storeLive(msg *nats.Msg) {
+	if !v.accept(msg) {
+		return
+	}
+	// JetStream deliveries carry when the stream stored them in their ack subject
+	if meta, err := msg.Metadata(); err == nil {
+		v.messages.StoreAt(msg, v.extractFrom(msg.Data), meta.Timestamp, meta.Sequence.Stream)
+	} else {
+		v.messages.Store(msg, v.extractFrom(msg.Data))
+	}
+	logger.Log.Debug("Message received", "subject", msg.Subject, "size", len(msg.Data))
+}
+
+// holdLive clears the stored messages and holds live ones for generation until releaseLive
+func (v *Viewer) holdLive(generation uint64) {
+	v.liveMu.Lock()
+	defer v.liveMu.Unlock()
+
+	v.messages.Clear()
+	v.holdGen = generation
+	v.held = nil
+}
+
+// liveReceived returns at least the number of live messages held so far or still queued to
+// be. The queue is counted first and one more is allowed for a message the handler has taken
+// off it but not held yet, so none are missed
+func (v *Viewer) liveReceived() int {
+	v.mu.Lock()
+	sub := v.sub
+	v.mu.Unlock()
+
+	received := 1
+	if sub != nil {
+		if queued, _, err := sub.Pending(); err == nil {
+			received += queued
+		}
+	}
+
+	v.liveMu.Lock()
+	defer v.liveMu.Unlock()
+
+	return received + len(v.held)
+}
+
+// releaseLive stores the live messages held during a replay after the replayed ones,
+// dropping them if the viewer has since switched subjects. Messages published while the
+// replay was finding where to stop are held but already part of the stream up to last, the
+// message it stopped at, so held messages up to last among the first window are skipped
+func (v *Viewer) releaseLive(generation uint64, last *nats.Msg, window int) {
+	v.liveMu.Lock()
+	defer v.liveMu.Unlock()
+
+	if v.holdGen != generation {
+		return
+	}
+	if v.generation.Load() == generation {
+		skip := 0
+		if last != nil {
+			for i := range min(window, len(v.held)) {
+				if sameMessage(v.held[i], last) {
+					skip = i + 1
+				}
+			}
+		}
+		for _, msg := range v.held[skip:] {
+			v.storeLive(msg)
+		}
+	}
+	v.holdGen = 0
+	v.held = nil
+}
+
+// sameMessage reports whether two messages have the same subject, payload and headers
+func sameMessage(a, b *nats.Msg) bool {
+	return a.Subject == b.Subject && bytes.Equal(a.Data, b.Data) && maps.EqualFunc(a.Header, b.Header, slices.Equal)
+}
+
+// accept reports whether a message passes the payload and header filters
+func (v *Viewer) accept(msg *nats.Msg) bool {
+	if re := v.filter.Load(); re != nil && !re.Match(msg.Data) {
+		return false
+	}
+	if headers := v.headers.Load(); headers != nil && !headers.Matches(msg.Header) {
+		return false
+	}
+	return true
+}
+
+// SetFilter only stores new messages whose payload matches re, or all messages if re is nil.
+// The filter is cleared when the Viewer watches another subject
+func (v *Viewer) SetFilter(re *regexp.Regexp) {
+	v.filter.Store(re)
+}
+
+// SetHeaderFilter only stores new messages carrying the filter's header, or all messages
+// if filter is nil. The filter is cleared when the Viewer watches another subject
+func (v *Viewer) SetHeaderFilter(filter *HeaderFilter) {
+	v.headers.Store(filter)
+}
+
+// HeaderFilter returns the current header filter, nil if none
+func (v *Viewer) HeaderFilter() *HeaderFilter {
+	return v.headers.Load()
+}
+
+// SetExtract pulls the value at path out of each message, or stops extracting if path is nil.
+// Stored messages are re-extracted so they match. The path is cleared when the Viewer
+// watches another subject
+func (v *Viewer) SetExtract(path *JSONPath) {
+	v.extract.Store(path)
+	v.messages.Each(func(msg *Message) {
+		msg.Extracted = v.extractFrom(msg.Data)
+	})
+}
+
+// SetDetectDuplicates flags messages repeating an earlier stored payload and Nats-Msg-Id.
+// It costs a hash per message, so it's off until enabled and when watching another subject
+func (v *Viewer) SetDetectDuplicates(enabled bool) {
+	v.messages.SetDetectDuplicates(enabled)
+}
+
+// DetectingDuplicates reports whether duplicate messages are being flagged
+func (v *Viewer) DetectingDuplicates() bool {
+	return v.messages.DetectingDuplicates()
+}
+
+// SetValidateJSON marks whether each message's payload is valid JSON. It's off until
+// enabled and when watching another subject, sparing binary subjects the parse
+func (v *Viewer) SetValidateJSON(enabled bool) {
+	v.messages.SetValidateJSON(enabled)
+}
+
+// ValidatingJSON reports whether payloads are being checked for valid JSON
+func (v *Viewer) ValidatingJSON() bool {
+	return v.messages.ValidatingJSON()
+}
+
+// Extract returns the current extract path, nil if none
+func (v *Viewer) Extract() *JSONPath {
+	return v.extract.Load()
+}
+
+// extractFrom evaluates the extract path against a payload, empty if unset or absent
+func (v *Viewer) extractFrom(data []byte) string {
+	path := v.extract.Load()
+	if path == nil {
+		return ""
+	}
+	value, _ := path.Extract(data)
+	return value
+}
+
 // Stops the Viewer from ingesting NATS messages
 func (v *Viewer) Stop() {
 	v.mu.Lock()
@@ -70,6 +262,11 @@ func (v *Viewer) Stop() {
 	logger.Log.Debug("Viewer has been stopped")
 }
 
+// Clear drops the stored messages while staying subscribed
+func (v *Viewer) Clear() {
+	v.messages.Clear()
+}
+
 // GetMessages returns all stored messages
 func (v *Viewer) GetMessages() []Message {
 	return v.messages.All()
@@ -79,3 +276,84 @@ func (v *Viewer) GetMessages() []Message {
 func (v *Viewer) GetMessageCount() int {
 	return v.messages.Count()
 }
+
+// Capacity returns the number of messages kept before the oldest are evicted
+func (v *Viewer) Capacity() int {
+	return v.messages.Capacity()
+}
+
+// SizeBytes estimates the memory held by the stored messages
+func (v *Viewer) SizeBytes() int {
+	return v.messages.SizeBytes()
+}
+
+// messageExport is the exported representation of a message
+type messageExport struct {
+	Subject   string              `json:"subject"`
+	Timestamp time.Time           `json:"timestamp"`
+	Sequence  uint64              `json:"sequence,omitempty"`
+	Data      []byte              `json:"data"` // Encoded as base64 so binary payloads round-trip
+	Headers   map[string][]string `json:"headers,omitempty"`
+}
+
+// Export writes all stored messages to w as newline-delimited JSON
+func (v *Viewer) Export(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for _, msg := range v.messages.All() {
+		err := encoder.Encode(messageExport{
+			Subject:   msg.Subject,
+			Timestamp: msg.Timestamp,
+			Sequence:  msg.Sequence,
+			Data:      msg.Data,
+			Headers:   msg.Headers,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dumpTimeLayout names dumped files by receive time, sorting in order and safe on any filesystem
+const dumpTimeLayout = "20060102T150405.000000000Z"
+
+// messageSidecar describes a dumped payload, written next to it
+type messageSidecar struct {
+	Subject   string              `json:"subject"`
+	Timestamp time.Time           `json:"timestamp"`
+	Sequence  uint64              `json:"sequence,omitempty"`
+	Size      int                 `json:"size"`
+	Headers   map[string][]string `json:"headers,omitempty"`
+}
+
+// Dump writes each stored message's payload verbatim to <timestamp>-<seq>.bin in dir, with
+// its subject and headers in a .json sidecar, creating dir if needed. It returns the number
+// of messages written
+func (v *Viewer) Dump(dir string) (int, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+
+	messages := v.messages.All()
+	for i, msg := range messages {
+		name := fmt.Sprintf("%s-%06d", msg.Timestamp.UTC().Format(dumpTimeLayout), i+1)
+		if err := os.WriteFile(filepath.Join(dir, name+".bin"), msg.Data, 0644); err != nil {
+			return i, err
+		}
+
+		sidecar, err := json.MarshalIndent(messageSidecar{
+			Subject:   msg.Subject,
+			Timestamp: msg.Timestamp,
+			Sequence:  msg.Sequence,
+			Size:      len(msg.Data),
+			Headers:   msg.Headers,
+		}, "", "  ")
+		if err != nil {
+			return i, err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+".json"), append(sidecar, '\n'), 0644); err != nil {
+			return i, err
+		}
+	}
+	return len(messages), nil
+}
@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+func TestViewerExport(t *testing.T) {
+	viewer := NewViewer(nil, 10)
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	viewer.messages.StoreAt(&nats.Msg{Subject: "orders.new", Data: []byte(`{"id":1}`)}, "", at, 0)
+	viewer.messages.StoreAt(&nats.Msg{
+		Subject: "orders.bin",
+		Data:    []byte{0x00, 0xff, 0x10},
+		Header:  nats.Header{"Nats-Msg-Id": []string{"abc"}},
+	}, "", at.Add(time.Second), 42)
+
+	var out strings.Builder
+	if err := viewer.Export(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"subject":"orders.new","timestamp":"2025-01-02T03:04:05Z","data":"eyJpZCI6MX0="}` + "\n" +
+		`{"subject":"orders.bin","timestamp":"2025-01-02T03:04:06Z","sequence":42,"data":"AP8Q","headers":{"Nats-Msg-Id":["abc"]}}` + "\n"
+	if out.String() != want {
+		t.Errorf("Export() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
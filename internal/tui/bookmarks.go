@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/eallender/nats-ls/internal/config"
+	"github.com/eallender/nats-ls/internal/logger"
+)
+
+// bookmarksFileName is the name of the bookmarks file in the config directory
+const bookmarksFileName = "bookmarks.json"
+
+// bookmarksPath returns the path of the bookmarks file
+func bookmarksPath() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, bookmarksFileName), nil
+}
+
+// loadBookmarks reads the bookmarked subjects, sorted
+func loadBookmarks() []string {
+	path, err := bookmarksPath()
+	if err != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Log.Warn("Failed to read bookmarks", "path", path, "error", err)
+		}
+		return nil
+	}
+
+	var bookmarks []string
+	if err := json.Unmarshal(data, &bookmarks); err != nil {
+		logger.Log.Warn("Failed to parse bookmarks", "path", path, "error", err)
+		return nil
+	}
+	slices.Sort(bookmarks)
+	return slices.Compact(bookmarks)
+}
+
+// saveBookmarks writes the bookmarked subjects as a JSON array
+func saveBookmarks(bookmarks []string) {
+	path, err := bookmarksPath()
+	if err != nil {
+		return
+	}
+
+	// Keep ">" readable for anyone editing the file by hand
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(bookmarks); err != nil {
+		return
+	}
+	if err := os.WriteFile(path, data.Bytes(), 0600); err != nil {
+		logger.Log.Warn("Failed to save bookmarks", "path", path, "error", err)
+	}
+}
+
+// isBookmarked reports whether the full subject, e.g. "orders.>" for a prefix, is bookmarked
+func (m Model) isBookmarked(subject string) bool {
+	_, found := slices.BinarySearch(m.bookmarks, subject)
+	return found
+}
+
+// toggleBookmark bookmarks the selected subject or prefix, or removes its bookmark
+func (m Model) toggleBookmark() Model {
+	nodes := m.getSubjectsAtCurrentLevel()
+	if m.selectedIndex >= len(nodes) {
+		return m
+	}
+	subject := nodes[m.selectedIndex].FullName(m.navPath, m.separator())
+
+	if m.isBookmarked(subject) {
+		m = m.removeBookmark(subject)
+		m.statusMessage = "Removed bookmark " + subject
+		return m
+	}
+
+	m.bookmarks = append(slices.Clone(m.bookmarks), subject)
+	slices.Sort(m.bookmarks)
+	saveBookmarks(m.bookmarks)
+	m.statusMessage = "Bookmarked " + subject
+	return m
+}
+
+// removeBookmark drops a bookmark, keeping the bookmarks list selection in range
+func (m Model) removeBookmark(subject string) Model {
+	m.bookmarks = slices.DeleteFunc(slices.Clone(m.bookmarks), func(bookmark string) bool {
+		return bookmark == subject
+	})
+	m.bookmarkIndex = max(min(m.bookmarkIndex, len(m.bookmarks)-1), 0)
+	saveBookmarks(m.bookmarks)
+	return m
+}
+
+// jumpToBookmark navigates the subject tree to a bookmarked subject and watches it
+func (m Model) jumpToBookmark(subject string) Model {
+	sep := m.separator()
+	if prefix, ok := strings.CutSuffix(subject, sep+">"); ok {
+		// Prefixes are selected under their parent, like a subject
+		tokens := strings.Split(prefix, sep)
+		m.navPath = append([]string{}, tokens[:len(tokens)-1]...)
+		m.selectedIndex = 0
+		for i, node := range m.getSubjectsAtCurrentLevel() {
+			if !node.IsLeaf && node.Name == tokens[len(tokens)-1] {
+				m.selectedIndex = i
+				break
+			}
+		}
+		if sep != "." {
+			m.statusMessage = prefixWatchUnsupported
+			return m
+		}
+	} else {
+		m = m.jumpToSubject(subject)
+	}
+	return m.watch(subject)
+}
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/atotto/clipboard"
+	"github.com/eallender/nats-ls/internal/logger"
+)
+
+// Clipboard writes text to a clipboard, allowing the system clipboard to be stubbed
+type Clipboard interface {
+	WriteAll(text string) error
+}
+
+// systemClipboard writes to the operating system clipboard
+type systemClipboard struct{}
+
+// errNoClipboard is returned when no clipboard utility is available, e.g. on headless systems
+var errNoClipboard = errors.New("no clipboard available")
+
+func (systemClipboard) WriteAll(text string) error {
+	if clipboard.Unsupported {
+		return errNoClipboard
+	}
+	return clipboard.WriteAll(text)
+}
+
+// copyToClipboard copies text and reports the result in the command bar
+func (m Model) copyToClipboard(what, text string) Model {
+	if m.clipboard == nil {
+		m.statusMessage = fmt.Sprintf("Copy failed: %v", errNoClipboard)
+		return m
+	}
+
+	if err := m.clipboard.WriteAll(text); err != nil {
+		logger.Log.Debug("Failed to copy to clipboard", "what", what, "error", err)
+		m.statusMessage = fmt.Sprintf("Copy failed: %v", err)
+		return m
+	}
+
+	m.statusMessage = fmt.Sprintf("Copied %s", what)
+	return m
+}
+
+// copySelectedSubject copies the full dotted name of the selected subject tree node,
+// as a wildcard for prefixes
+func (m Model) copySelectedSubject() Model {
+	nodes := m.getSubjectsAtCurrentLevel()
+	if m.selectedIndex >= len(nodes) {
+		return m
+	}
+
+	subject := nodes[m.selectedIndex].FullName(m.navPath, m.separator())
+	return m.copyToClipboard(subject, subject)
+}
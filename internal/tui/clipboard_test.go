@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/eallender/nats-ls/internal/config"
+	"github.com/eallender/nats-ls/internal/monitor"
+)
+
+// stubClipboard records what was copied, or fails with err if set
+type stubClipboard struct {
+	text string
+	err  error
+}
+
+func (c *stubClipboard) WriteAll(text string) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.text = text
+	return nil
+}
+
+func TestCopySelectedSubject(t *testing.T) {
+	m := pausedModel(&config.Config{},
+		monitor.SubjectSnapshot{Name: "orders", MessageCount: 1},
+		monitor.SubjectSnapshot{Name: "orders.new", MessageCount: 1},
+	)
+	clipboard := &stubClipboard{}
+	m.clipboard = clipboard
+
+	// The prefix node sorts after the leaf of the same name
+	m.selectedIndex = 1
+	m = m.copySelectedSubject()
+	if clipboard.text != "orders.>" {
+		t.Errorf("copied %q, want orders.>", clipboard.text)
+	}
+	if m.statusMessage != "Copied orders.>" {
+		t.Errorf("status = %q, want Copied orders.>", m.statusMessage)
+	}
+}
+
+func TestCopyToClipboardFailure(t *testing.T) {
+	m := Model{clipboard: &stubClipboard{err: errors.New("no display")}}
+	m = m.copyToClipboard("payload", "data")
+	if m.statusMessage != "Copy failed: no display" {
+		t.Errorf("status = %q, want Copy failed: no display", m.statusMessage)
+	}
+
+	m = Model{}.copyToClipboard("payload", "data")
+	if m.statusMessage != "Copy failed: no clipboard available" {
+		t.Errorf("status without a clipboard = %q, want Copy failed: no clipboard available", m.statusMessage)
+	}
+}
@@ -0,0 +1,425 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/eallender/nats-ls/internal/logger"
+	"github.com/eallender/nats-ls/internal/monitor"
+)
+
+// runCommand parses and executes a command entered in the command bar
+func (m Model) runCommand(input string) (Model, tea.Cmd) {
+	verb, arg, _ := strings.Cut(strings.TrimSpace(input), " ")
+	arg = strings.TrimSpace(arg)
+
+	switch verb {
+	case "":
+		return m, nil
+	case "filter":
+		return m.setFilter(arg), nil
+	case "export":
+		return m.exportSubjects(arg), nil
+	case "export-messages":
+		return m.exportMessages(arg), nil
+	case "dump":
+		return m.dumpMessages(arg), nil
+	case "pub":
+		return m.publish(arg)
+	case "msgfilter":
+		return m.setMessageFilter(arg), nil
+	case "hfilter":
+		return m.setHeaderFilter(arg), nil
+	case "sub":
+		return m.subscribe(arg), nil
+	case "goto":
+		return m.gotoSubject(arg), nil
+	case "extract":
+		return m.setExtract(arg), nil
+	case "replay":
+		return m.replay(arg)
+	case "connect":
+		return m.connect(arg)
+	default:
+		logger.Log.Debug("Unknown command", "command", verb)
+		m.statusMessage = fmt.Sprintf("Unknown command: %s", verb)
+		return m, nil
+	}
+}
+
+// connect switches to another NATS server, keeping the rest of the config. The current
+// connection and its monitors are closed and the usual reconnect loop takes over, so a
+// server that can't be reached leaves nats-ls disconnected and retrying it
+func (m Model) connect(address string) (Model, tea.Cmd) {
+	if address == "" {
+		m.statusMessage = "Usage: :connect <address>"
+		return m, nil
+	}
+
+	cfg := *m.config
+	cfg.NatsAddress = address
+	if err := cfg.ValidateAddress(); err != nil {
+		m.statusMessage = err.Error()
+		return m, nil
+	}
+
+	// The address may carry credentials, keep them out of the log and the header
+	shown := cfg.RedactedAddress()
+	logger.Log.Info("Switching NATS server", "from", m.serverURL, "to", shown)
+	closeOld := closeConnectionCmd(m.nc, m.viewer, m.discovery, m.jetstream, m.config.NatsDrainTimeout())
+	if m.metrics != nil {
+		m.metrics.SetSource(nil, nil)
+	}
+	m.config = &cfg
+	m.serverURL = shown
+	m.nc = nil
+	m.viewer = nil
+	m.discovery = nil
+	m.jetstream = nil
+	m.connectErr = nil
+	m.connectTries = 0
+	m.switchingTo = shown
+	m.rtt, m.rttErr = 0, nil
+
+	// Everything shown came from the old server
+	m.navPath = nil
+	m.selectedIndex = 0
+	m.watching = ""
+	m.detailMessage = nil
+	m.messageFilter = ""
+	m.paused = false
+	m.pausedSubjects = nil
+	m.pausedStreams = nil
+	m.consumerStream = ""
+	m.consumers = nil
+	m.consumersErr = nil
+	m.showRecent = false
+	m.subjectDetail = ""
+
+	// The tick loop or the attempt in flight picks up the new address
+	var spin tea.Cmd
+	m, spin = m.startSpinner()
+	return m, tea.Batch(closeOld, spin)
+}
+
+// setFilter applies a NATS wildcard filter to the subject tree, or clears it if empty
+func (m Model) setFilter(pattern string) Model {
+	m.filter = pattern
+	m.navPath = nil
+	m.selectedIndex = 0
+	if pattern == "" {
+		logger.Log.Debug("Subject filter cleared")
+	} else {
+		logger.Log.Debug("Subject filter applied", "pattern", pattern)
+	}
+	return m
+}
+
+// setHeaderFilter restricts the watched subject's new messages to those carrying a header,
+// given as "key" for any value or "key=value", or clears the restriction if empty
+func (m Model) setHeaderFilter(arg string) Model {
+	if m.watching == "" || m.viewer == nil {
+		m.statusMessage = "Not watching a subject, nothing to filter"
+		return m
+	}
+
+	if arg == "" {
+		m.viewer.SetHeaderFilter(nil)
+		logger.Log.Debug("Header filter cleared", "subject", m.watching)
+		return m
+	}
+
+	filter, err := monitor.ParseHeaderFilter(arg)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Usage: :hfilter <key>[=<value>] (%v)", err)
+		return m
+	}
+
+	m.viewer.SetHeaderFilter(filter)
+	logger.Log.Debug("Header filter applied", "subject", m.watching, "filter", filter.String())
+	return m
+}
+
+// subscribe watches a subject or wildcard entered by hand, independent of the subject tree,
+// or stops watching if empty
+func (m Model) subscribe(pattern string) Model {
+	if m.viewer == nil {
+		m.statusMessage = "Not connected"
+		return m
+	}
+
+	// Switch to the message view from wherever the user is
+	m.showLogs = false
+	m.logLines = nil
+	m.showDiagnostics = false
+	m.subjectDetail = ""
+	m.showBookmarks = false
+	m.showRecent = false
+	m.consumerStream = ""
+	m.consumers = nil
+	m.consumersErr = nil
+	return m.watch(pattern)
+}
+
+// gotoSubject opens the subject tree at a discovered subject or prefix, e.g. "orders.us",
+// instead of drilling down one level at a time
+func (m Model) gotoSubject(path string) Model {
+	sep := m.separator()
+	path = strings.TrimSuffix(path, sep)
+	if path == "" {
+		m.statusMessage = "Usage: :goto <subject>"
+		return m
+	}
+
+	isSubject, isPrefix := false, false
+	for _, name := range m.visibleSubjectNames() {
+		isSubject = isSubject || name == path
+		isPrefix = isPrefix || strings.HasPrefix(name, path+sep)
+	}
+	if !isSubject && !isPrefix {
+		m.statusMessage = fmt.Sprintf("No subject %s", path)
+		return m
+	}
+
+	// Switch to the subject tree from wherever the user is
+	if m.watching != "" {
+		m = m.watch("")
+	}
+	m.showLogs = false
+	m.logLines = nil
+	m.showDiagnostics = false
+	m.subjectDetail = ""
+	m.showBookmarks = false
+	m.showRecent = false
+	m.consumerStream = ""
+	m.consumers = nil
+	m.consumersErr = nil
+
+	// Open prefixes to list what's below them, select subjects and collapsed prefixes in their parent
+	tokens := strings.Split(path, sep)
+	if depth := m.maxDepth(); !isPrefix || (depth > 0 && len(tokens) >= depth) {
+		return m.jumpToSubject(path)
+	}
+	m.navPath = tokens
+	m.selectedIndex = 0
+	return m
+}
+
+// setMessageFilter restricts the watched subject's new messages to payloads matching
+// a regular expression, or clears the restriction if empty
+func (m Model) setMessageFilter(pattern string) Model {
+	if m.watching == "" || m.viewer == nil {
+		m.statusMessage = "Not watching a subject, nothing to filter"
+		return m
+	}
+
+	if pattern == "" {
+		m.viewer.SetFilter(nil)
+		m.messageFilter = ""
+		logger.Log.Debug("Message filter cleared", "subject", m.watching)
+		return m
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Invalid message filter: %v", err)
+		return m
+	}
+
+	m.viewer.SetFilter(re)
+	m.messageFilter = pattern
+	logger.Log.Debug("Message filter applied", "subject", m.watching, "pattern", pattern)
+	return m
+}
+
+// setExtract shows the value at a JSON path as a column in the message list,
+// or removes the column if empty
+func (m Model) setExtract(expr string) Model {
+	if m.watching == "" || m.viewer == nil {
+		m.statusMessage = "Not watching a subject, nothing to extract from"
+		return m
+	}
+
+	if expr == "" {
+		m.viewer.SetExtract(nil)
+		logger.Log.Debug("Extract path cleared", "subject", m.watching)
+		return m
+	}
+
+	path, err := monitor.ParseJSONPath(expr)
+	if err != nil {
+		m.statusMessage = err.Error()
+		return m
+	}
+
+	m.viewer.SetExtract(path)
+	logger.Log.Debug("Extract path applied", "subject", m.watching, "path", expr)
+	return m
+}
+
+// replay replaces the watched subject's messages with those stored in its JetStream stream,
+// parsed as "all", "last <n>", or "since <duration|time>". New messages keep arriving after
+func (m Model) replay(arg string) (Model, tea.Cmd) {
+	if m.watching == "" || m.viewer == nil {
+		m.statusMessage = "Not watching a subject, nothing to replay"
+		return m, nil
+	}
+	if m.jetstream == nil {
+		m.statusMessage = "JetStream is disabled, nothing to replay"
+		return m, nil
+	}
+
+	start, err := monitor.ParseReplayStart(arg)
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Usage: :replay all|last <n>|since <duration> (%v)", err)
+		return m, nil
+	}
+
+	// The replay clears the messages and holds live ones until it's done, so history reads in order
+	m.selectedMessageIndex = 0
+	m.autoScroll = true
+	m.statusMessage = fmt.Sprintf("Replaying %s...", m.watching)
+	return m, m.replayCmd(m.watching, start)
+}
+
+// exportSubjects writes the discovered subjects to a JSON file, or CSV or a Graphviz DOT
+// graph if the path ends in .csv or .dot
+func (m Model) exportSubjects(path string) Model {
+	if path == "" {
+		m.statusMessage = "Usage: :export <file.json|file.csv|file.dot>"
+		return m
+	}
+	if m.discovery == nil {
+		m.statusMessage = "Not connected, nothing to export"
+		return m
+	}
+
+	subjects := m.discovery.GetAllSubjects()
+	err := writeFile(path, func(w io.Writer) error {
+		switch ext := filepath.Ext(path); {
+		case strings.EqualFold(ext, ".csv"):
+			return monitor.ExportSubjectsCSV(w, subjects)
+		case strings.EqualFold(ext, ".dot"):
+			return monitor.ExportSubjectsDOT(w, subjects, m.separator())
+		}
+		return monitor.ExportSubjects(w, subjects)
+	})
+	if err != nil {
+		logger.Log.Warn("Failed to export subjects", "path", path, "error", err)
+		m.statusMessage = fmt.Sprintf("Export failed: %v", err)
+		return m
+	}
+
+	logger.Log.Info("Exported subjects", "path", path, "count", len(subjects))
+	m.statusMessage = fmt.Sprintf("Exported %d subjects to %s", len(subjects), path)
+	return m
+}
+
+// exportMessages writes the watched subject's captured messages to an NDJSON file
+func (m Model) exportMessages(path string) Model {
+	if path == "" {
+		m.statusMessage = "Usage: :export-messages <file.ndjson>"
+		return m
+	}
+	if m.watching == "" || m.viewer == nil {
+		m.statusMessage = "Not watching a subject, nothing to export"
+		return m
+	}
+
+	if err := writeFile(path, m.viewer.Export); err != nil {
+		logger.Log.Warn("Failed to export messages", "path", path, "error", err)
+		m.statusMessage = fmt.Sprintf("Export failed: %v", err)
+		return m
+	}
+
+	count := m.viewer.GetMessageCount()
+	logger.Log.Info("Exported messages", "path", path, "subject", m.watching, "count", count)
+	m.statusMessage = fmt.Sprintf("Exported %d messages to %s", count, path)
+	return m
+}
+
+// dumpMessages writes each of the watched subject's captured messages to its own file in a directory
+func (m Model) dumpMessages(dir string) Model {
+	if dir == "" {
+		m.statusMessage = "Usage: :dump <dir>"
+		return m
+	}
+	if m.watching == "" || m.viewer == nil {
+		m.statusMessage = "Not watching a subject, nothing to dump"
+		return m
+	}
+
+	count, err := m.viewer.Dump(dir)
+	if err != nil {
+		logger.Log.Warn("Failed to dump messages", "dir", dir, "written", count, "error", err)
+		m.statusMessage = fmt.Sprintf("Dump failed after %d messages: %v", count, err)
+		return m
+	}
+
+	logger.Log.Info("Dumped messages", "dir", dir, "subject", m.watching, "count", count)
+	m.statusMessage = fmt.Sprintf("Dumped %d messages to %s", count, dir)
+	return m
+}
+
+// publish sends a message, or a request if prefixed with -r, parsed as "[-r] <subject> <payload>"
+func (m Model) publish(arg string) (Model, tea.Cmd) {
+	request := false
+	if fields := strings.Fields(arg); len(fields) > 0 && fields[0] == "-r" {
+		request = true
+		arg = strings.TrimSpace(strings.TrimPrefix(arg, "-r"))
+	}
+
+	// The payload starts after the whitespace ending the subject, keeping any that follows
+	subject, payload := arg, ""
+	if i := strings.IndexFunc(arg, unicode.IsSpace); i >= 0 {
+		_, size := utf8.DecodeRuneInString(arg[i:])
+		subject, payload = arg[:i], arg[i+size:]
+	}
+	if subject == "" {
+		m.statusMessage = "Usage: :pub [-r] <subject> <payload>"
+		return m, nil
+	}
+	if !m.IsConnected() {
+		m.statusMessage = "Not connected, cannot publish"
+		return m, nil
+	}
+
+	if request {
+		m.statusMessage = fmt.Sprintf("Waiting for reply on %s...", subject)
+		return m, m.requestCmd(subject, []byte(payload))
+	}
+
+	if err := m.nc.Publish(subject, []byte(payload)); err != nil {
+		logger.Log.Warn("Failed to publish", "subject", subject, "error", err)
+		m.statusMessage = fmt.Sprintf("Publish failed: %v", err)
+		return m, nil
+	}
+
+	logger.Log.Debug("Published message", "subject", subject, "size", len(payload))
+	m.statusMessage = fmt.Sprintf("Published %d bytes to %s", len(payload), subject)
+	return m, nil
+}
+
+// writeFile creates the file at path and writes to it with write
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -5,6 +5,9 @@ package tui
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -25,54 +28,144 @@ func (m Model) Init() tea.Cmd {
 
 // tryConnect attempts to connect to NATS and returns a command
 func (m Model) tryConnect() tea.Msg {
-	nc, err := nats.Connect(
-		m.config.NatsAddress,
-		nats.MaxReconnects(m.config.NatsMaxReconnects),
-		nats.ReconnectWait(time.Duration(m.config.NatsReconnectWaitSeconds)*time.Second),
-		nats.DisconnectErrHandler(func(nc *nats.Conn, err error) {
-			if err != nil {
-				logger.Log.Warn("Disconnected from NATS", "error", err)
-			} else {
-				logger.Log.Info("Disconnected from NATS")
-			}
-		}),
-		nats.ReconnectHandler(func(nc *nats.Conn) {
-			logger.Log.Info("Reconnected to NATS", "address", nc.ConnectedUrl())
-		}),
-		nats.ClosedHandler(func(nc *nats.Conn) {
-			logger.Log.Debug("NATS connection closed")
-		}),
-	)
+	nc, err := monitor.Connect(m.config)
 
 	if err != nil {
 		logger.Log.Debug("Connection attempt failed", "error", err)
-		return connectAttemptMsg{nc: nil, err: err}
+		return connectAttemptMsg{address: m.config.NatsAddress, nc: nil, err: err}
 	}
 
-	logger.Log.Info("Connected to NATS", "address", m.config.NatsAddress)
-	viewer := monitor.NewViewer(nc, m.config.NatsViewerMessageLimit)
-	discovery := monitor.NewDiscovery(nc)
-
-	// Start discovery to listen for all subjects
-	ctx := context.Background()
-	if err := discovery.Start(ctx, m.config.NatsDiscoveryPendingLimit, m.config.NatsDiscoveryStorageLimitMB); err != nil {
-		logger.Log.Warn("Failed to start discovery", "error", err)
-	}
+	logger.Log.Info("Connected to NATS", "address", m.config.RedactedAddress())
+	viewer, discovery, jetstream := startMonitors(nc, m.config)
 
 	return connectAttemptMsg{
+		address:   m.config.NatsAddress,
 		nc:        nc,
 		viewer:    viewer,
 		discovery: discovery,
+		jetstream: jetstream,
 		err:       nil,
 	}
 }
 
+// closeConnection stops a connection's monitors and drains it, any of which may be nil
+func closeConnection(nc *nats.Conn, viewer *monitor.Viewer, discovery *monitor.Discovery, jetstream *monitor.JetStream, drainTimeout time.Duration) {
+	if viewer != nil {
+		viewer.Stop()
+	}
+	if discovery != nil {
+		discovery.Stop()
+	}
+	if jetstream != nil {
+		jetstream.Stop()
+	}
+	if nc == nil {
+		return
+	}
+	if nc.IsConnected() {
+		monitor.Drain(nc, drainTimeout)
+	} else {
+		// Stop a lost connection from reconnecting in the background
+		nc.Close()
+	}
+}
+
+// closeConnectionCmd returns a command that closes a connection without blocking the UI on the drain
+func closeConnectionCmd(nc *nats.Conn, viewer *monitor.Viewer, discovery *monitor.Discovery, jetstream *monitor.JetStream, drainTimeout time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		closeConnection(nc, viewer, discovery, jetstream, drainTimeout)
+		return nil
+	}
+}
+
+// fetchConsumersCmd returns a command that fetches the consumers of a JetStream stream
+func (m Model) fetchConsumersCmd(stream string) tea.Cmd {
+	js := m.jetstream
+	return func() tea.Msg {
+		consumers, err := js.GetConsumers(context.Background(), stream)
+		if err != nil {
+			logger.Log.Debug("Failed to list JetStream consumers", "stream", stream, "error", err)
+		}
+		return consumersMsg{stream: stream, consumers: consumers, err: err}
+	}
+}
+
+// requestCmd returns a command that sends a request and reports the reply with its round-trip time
+func (m Model) requestCmd(subject string, payload []byte) tea.Cmd {
+	nc := m.nc
+	timeout := m.config.NatsRequestTimeout()
+	return func() tea.Msg {
+		start := time.Now()
+		reply, err := nc.Request(subject, payload, timeout)
+		elapsed := time.Since(start)
+		switch {
+		case errors.Is(err, nats.ErrNoResponders):
+			return statusMsg(fmt.Sprintf("No responders on %s", subject))
+		case errors.Is(err, nats.ErrTimeout):
+			return statusMsg(fmt.Sprintf("No reply from %s within %s", subject, timeout))
+		case err != nil:
+			logger.Log.Debug("Request failed", "subject", subject, "error", err)
+			return statusMsg(fmt.Sprintf("Request to %s failed: %v", subject, err))
+		}
+		return statusMsg(fmt.Sprintf("Reply from %s in %s: %s", subject, elapsed.Round(time.Microsecond), strings.ReplaceAll(string(reply.Data), "\n", " ")))
+	}
+}
+
+// replayCmd returns a command that replays a subject's messages from the stream storing it
+func (m Model) replayCmd(subject string, start monitor.ReplayStart) tea.Cmd {
+	js, viewer := m.jetstream, m.viewer
+	return func() tea.Msg {
+		stream, err := js.StreamForSubject(context.Background(), subject)
+		if monitor.IsStreamNotFound(err) {
+			return statusMsg(fmt.Sprintf("No stream stores %s", subject))
+		}
+		if err != nil {
+			logger.Log.Debug("Failed to find stream", "subject", subject, "error", err)
+			return statusMsg(fmt.Sprintf("Replay of %s failed: %v", subject, err))
+		}
+
+		count, err := viewer.Replay(context.Background(), js, stream, subject, start)
+		if err != nil {
+			logger.Log.Warn("Replay failed", "stream", stream, "subject", subject, "error", err)
+			return statusMsg(fmt.Sprintf("Replay of %s failed after %d messages: %v", subject, count, err))
+		}
+		return statusMsg(fmt.Sprintf("Replayed %d messages (%s) from stream %s", count, start, stream))
+	}
+}
+
 // tickCmd sends a tick message after a delay to refresh the UI and retry connections
 func tickCmd() tea.Msg {
 	time.Sleep(1 * time.Second)
 	return tickMsg(time.Now())
 }
 
+// rttCmd samples the connection's round-trip latency off the UI goroutine, since a slow
+// or stalled server can take up to the connection's timeout to answer
+func rttCmd(nc *nats.Conn) tea.Cmd {
+	return func() tea.Msg {
+		rtt, err := nc.RTT()
+		return rttMsg{nc: nc, rtt: rtt, err: err}
+	}
+}
+
+// spinnerInterval is how often the reconnecting spinner advances
+const spinnerInterval = 100 * time.Millisecond
+
+// spinnerCmd sends a spinner message after spinnerInterval
+func spinnerCmd() tea.Msg {
+	time.Sleep(spinnerInterval)
+	return spinnerMsg{}
+}
+
+// startSpinner schedules the reconnecting spinner unless it's already running
+func (m Model) startSpinner() (Model, tea.Cmd) {
+	if m.spinning {
+		return m, nil
+	}
+	m.spinning = true
+	return m, spinnerCmd
+}
+
 // IsConnected checks if we're connected to NATS
 func (m Model) IsConnected() bool {
 	return m.nc != nil && m.nc.IsConnected()
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import (
+	"sort"
+	"strings"
+)
+
+// subjectArgPositions maps commands taking a subject argument to the argument's
+// position after the verb. Flags before the subject are skipped
+var subjectArgPositions = map[string]int{
+	"filter": 0,
+	"pub":    0,
+	"sub":    0,
+	"goto":   0,
+}
+
+// completionWord splits the command input into the text before the word being
+// completed and the word itself, if that word is a subject argument
+func completionWord(input string) (base, word string, ok bool) {
+	fields := strings.Split(input, " ")
+	if len(fields) < 2 {
+		return "", "", false
+	}
+
+	position, ok := subjectArgPositions[fields[0]]
+	if !ok {
+		return "", "", false
+	}
+
+	// Find the index of the subject argument, skipping flags like -r
+	argIndex := 1
+	for argIndex < len(fields)-1 && strings.HasPrefix(fields[argIndex], "-") {
+		argIndex++
+	}
+	argIndex += position
+	if argIndex != len(fields)-1 {
+		return "", "", false
+	}
+
+	word = fields[len(fields)-1]
+	return input[:len(input)-len(word)], word, true
+}
+
+// commonPrefix returns the longest prefix shared by all values
+func commonPrefix(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	prefix := values[0]
+	for _, value := range values[1:] {
+		for !strings.HasPrefix(value, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// complete handles a tab press in the command bar. The first press extends the
+// subject argument to the common prefix of matching subjects, later presses cycle
+// through the matches
+func (m Model) complete() Model {
+	if len(m.completions) > 1 {
+		m.completionIndex = (m.completionIndex + 1) % len(m.completions)
+		m.commandInput = m.completionBase + m.completions[m.completionIndex]
+		return m
+	}
+
+	base, word, ok := completionWord(m.commandInput)
+	if !ok || m.discovery == nil {
+		return m
+	}
+
+	var candidates []string
+	for _, subject := range m.discovery.GetAllSubjects() {
+		if strings.HasPrefix(subject.Name, word) {
+			candidates = append(candidates, subject.Name)
+		}
+	}
+	if len(candidates) == 0 {
+		return m
+	}
+	sort.Strings(candidates)
+
+	m.commandInput = base + commonPrefix(candidates)
+	if len(candidates) > 1 {
+		m.completions = candidates
+		m.completionBase = base
+		m.completionIndex = -1
+	}
+	return m
+}
+
+// clearCompletions ends tab completion cycling
+func (m Model) clearCompletions() Model {
+	m.completions = nil
+	m.completionBase = ""
+	m.completionIndex = 0
+	return m
+}
@@ -0,0 +1,29 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import "testing"
+
+func TestCompletionWord(t *testing.T) {
+	tests := []struct {
+		input string
+		base  string
+		word  string
+		ok    bool
+	}{
+		{"filter ord", "filter ", "ord", true},
+		{"pub -r ord", "pub -r ", "ord", true},
+		{"pub orders hel", "", "", false},
+		{"sub ord", "sub ", "ord", true},
+		{"goto orders.n", "goto ", "orders.n", true},
+		{"export ord", "", "", false},
+		{"goto", "", "", false},
+	}
+	for _, tt := range tests {
+		base, word, ok := completionWord(tt.input)
+		if base != tt.base || word != tt.word || ok != tt.ok {
+			t.Errorf("completionWord(%q) = %q, %q, %v; want %q, %q, %v", tt.input, base, word, ok, tt.base, tt.word, tt.ok)
+		}
+	}
+}
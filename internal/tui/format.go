@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import (
+	"encoding/hex"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/eallender/nats-ls/internal/config"
+	"github.com/eallender/nats-ls/internal/logger"
+	"github.com/eallender/nats-ls/internal/monitor"
+)
+
+// newDecoderRegistry creates the payload decoders with the configured subject rules,
+// matched on subjects split on sep, skipping rules naming an unknown decoder
+func newDecoderRegistry(rules []config.PayloadDecoder, sep string) *monitor.DecoderRegistry {
+	decoders := monitor.NewDecoderRegistry(sep)
+	for _, rule := range rules {
+		if err := decoders.Use(rule.Subject, rule.Decoder); err != nil {
+			logger.Log.Warn("Ignoring payload decoder", "subject", rule.Subject, "error", err)
+		}
+	}
+	return decoders
+}
+
+// hexDump renders data as offset/hex/ASCII lines of 16 bytes, like `hexdump -C`
+func hexDump(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	return strings.TrimSuffix(hex.Dump(data), "\n")
+}
+
+// spinnerFrames are the frames of the reconnecting spinner
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// sparkBlocks are the bar heights used by sparkline, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders counts as a bar per value scaled to the largest value,
+// keeping only the most recent width values
+func sparkline(counts []int, width int) string {
+	if width <= 0 || len(counts) == 0 {
+		return ""
+	}
+	if len(counts) > width {
+		counts = counts[len(counts)-width:]
+	}
+
+	peak := 0
+	for _, count := range counts {
+		peak = max(peak, count)
+	}
+
+	var b strings.Builder
+	for _, count := range counts {
+		level := 0
+		if peak > 0 {
+			level = count * (len(sparkBlocks) - 1) / peak
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
+
+// sizeBuckets are the payload size ranges counted by sizeHistogram, smallest first.
+// Each bucket holds sizes below its limit, the last has no limit
+var sizeBuckets = []struct {
+	label string
+	limit int
+}{
+	{"<1KB", 1 << 10},
+	{"1–10KB", 10 << 10},
+	{"10–100KB", 100 << 10},
+	{">100KB", 0},
+}
+
+// sizeHistogram counts messages per sizeBuckets label by payload size
+func sizeHistogram(messages []monitor.Message) map[string]int {
+	histogram := make(map[string]int, len(sizeBuckets))
+	for _, msg := range messages {
+		for _, bucket := range sizeBuckets {
+			if bucket.limit == 0 || len(msg.Data) < bucket.limit {
+				histogram[bucket.label]++
+				break
+			}
+		}
+	}
+	return histogram
+}
+
+// printableRatio returns the fraction of a payload's characters that are printable text,
+// counting each invalid UTF-8 byte as a non-printable character. Empty payloads are 1
+func printableRatio(data []byte) float64 {
+	if len(data) == 0 {
+		return 1
+	}
+
+	printable, total := 0, 0
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		data = data[size:]
+		total++
+		if r != utf8.RuneError && (unicode.IsPrint(r) || r == '\n' || r == '\r' || r == '\t') {
+			printable++
+		}
+	}
+	return float64(printable) / float64(total)
+}
+
+// Payload glyphs in the message list, a payload is binary below minPrintableRatio
+const (
+	textGlyph         = "≡"
+	binaryGlyph       = "▒"
+	minPrintableRatio = 0.9
+)
+
+// payloadGlyph marks a payload as text or binary by how much of it is printable
+func payloadGlyph(data []byte) string {
+	if printableRatio(data) < minPrintableRatio {
+		return binaryGlyph
+	}
+	return textGlyph
+}
+
+// previewText makes a payload preview printable on a single line, replacing control
+// characters and invalid UTF-8 such as a multi-byte character cut off by the preview length
+func previewText(preview string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == utf8.RuneError:
+			return '.'
+		case unicode.IsControl(r):
+			return ' '
+		default:
+			return r
+		}
+	}, preview)
+}
+
+// previewPayload returns the payload shown in the message list, decoded only when a
+// configured rule names a decoder for the subject so unconfigured lists stay raw
+func (m Model) previewPayload(msg monitor.Message) string {
+	data, _ := truncatePayload(msg.Data, m.maxDisplayBytes())
+	if _, ok := m.decoders.Match(msg.Subject); !ok {
+		return string(data)
+	}
+	text, _ := m.decoders.Decode(msg.Subject, data)
+	// Decoders lay payloads out over lines, fold them back onto the row
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// maxDisplayBytes returns how much of a payload is rendered, 0 for all of it
+func (m Model) maxDisplayBytes() int {
+	if m.config == nil {
+		return 0
+	}
+	return max(m.config.NatsViewerMaxDisplayBytes, 0)
+}
+
+// truncatePayload cuts data to at most limit bytes, backing up to the start of a UTF-8
+// character so text payloads don't end in a broken one. It returns the kept bytes and
+// how many were cut, keeping everything if limit is 0
+func truncatePayload(data []byte, limit int) ([]byte, int) {
+	if limit <= 0 || len(data) <= limit {
+		return data, 0
+	}
+
+	cut := limit
+	for i := limit; i > 0 && i > limit-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			cut = i
+			break
+		}
+	}
+	return data[:cut], len(data) - cut
+}
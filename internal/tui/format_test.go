@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/eallender/nats-ls/internal/monitor"
+)
+
+func TestHexDump(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"empty", nil, ""},
+		{"partial line", []byte("hi"), "00000000  68 69                                             |hi|"},
+		{
+			"non-printable bytes on a partial last line",
+			[]byte("hello, nats-ls!!\x00\x01\xffA"),
+			"00000000  68 65 6c 6c 6f 2c 20 6e  61 74 73 2d 6c 73 21 21  |hello, nats-ls!!|\n" +
+				"00000010  00 01 ff 41                                       |...A|",
+		},
+	}
+	for _, tt := range tests {
+		if got := hexDump(tt.data); got != tt.want {
+			t.Errorf("%s: hexDump() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSizeHistogram(t *testing.T) {
+	var messages []monitor.Message
+	for _, size := range []int{0, 1023, 1024, 10<<10 - 1, 10 << 10, 100<<10 - 1, 100 << 10, 1 << 20} {
+		messages = append(messages, monitor.Message{Data: make([]byte, size)})
+	}
+
+	want := map[string]int{"<1KB": 2, "1–10KB": 2, "10–100KB": 2, ">100KB": 2}
+	if got := sizeHistogram(messages); !maps.Equal(got, want) {
+		t.Errorf("sizeHistogram() = %v, want %v", got, want)
+	}
+	if got := sizeHistogram(nil); len(got) != 0 {
+		t.Errorf("sizeHistogram(nil) = %v, want empty", got)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts []int
+		width  int
+		want   string
+	}{
+		{"no width", []int{1, 2, 3}, 0, ""},
+		{"negative width", []int{1, 2, 3}, -1, ""},
+		{"no counts", nil, 5, ""},
+		{"all zeros", []int{0, 0, 0}, 5, "▁▁▁"},
+		{"single peak", []int{0, 0, 7, 0}, 5, "▁▁█▁"},
+		{"scaled to the peak", []int{0, 1, 2, 3, 4, 5, 6, 7}, 8, "▁▂▃▄▅▆▇█"},
+		{"keeps the most recent", []int{9, 0, 1, 2}, 3, "▁▄█"},
+	}
+	for _, tt := range tests {
+		if got := sparkline(tt.counts, tt.width); got != tt.want {
+			t.Errorf("%s: sparkline(%v, %d) = %q, want %q", tt.name, tt.counts, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestPrintableRatio(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  []byte
+		ratio float64
+		glyph string
+	}{
+		{"empty", nil, 1, textGlyph},
+		{"ascii", []byte("hello\tworld\r\n"), 1, textGlyph},
+		{"utf-8 multibyte", []byte("注文 📦"), 1, textGlyph},
+		{"invalid utf-8", []byte{0xff, 0xfe, 0xfd, 0xfc}, 0, binaryGlyph},
+		{"control characters", []byte{0x00, 0x01, 'a', 'b'}, 0.5, binaryGlyph},
+		// 9 of 10 characters printable is the lowest ratio still shown as text
+		{"mixed at the boundary", []byte("abcdefgh注\x00"), 0.9, textGlyph},
+		{"mixed below the boundary", []byte("abcdefgh\xff\x00"), 0.8, binaryGlyph},
+	}
+	for _, tt := range tests {
+		if got := printableRatio(tt.data); got != tt.ratio {
+			t.Errorf("%s: printableRatio() = %v, want %v", tt.name, got, tt.ratio)
+		}
+		if got := payloadGlyph(tt.data); got != tt.glyph {
+			t.Errorf("%s: payloadGlyph() = %q, want %q", tt.name, got, tt.glyph)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eallender/nats-ls/internal/config"
+	"github.com/eallender/nats-ls/internal/logger"
+)
+
+// historyFileName is the name of the command history file in the config directory
+const historyFileName = "history"
+
+// historyPath returns the path of the command history file
+func historyPath() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, historyFileName), nil
+}
+
+// loadHistory reads previously entered commands, oldest first
+func loadHistory() []string {
+	path, err := historyPath()
+	if err != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Log.Warn("Failed to read command history", "path", path, "error", err)
+		}
+		return nil
+	}
+
+	var history []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			history = append(history, line)
+		}
+	}
+	return history
+}
+
+// saveHistory writes the command history, one command per line
+func saveHistory(history []string) {
+	path, err := historyPath()
+	if err != nil {
+		return
+	}
+
+	data := strings.Join(history, "\n") + "\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		logger.Log.Warn("Failed to save command history", "path", path, "error", err)
+	}
+}
+
+// addToHistory records a command, dropping the oldest entries past the configured size.
+// Commands connecting with credentials aren't recorded
+func (m Model) addToHistory(command string) Model {
+	command = strings.TrimSpace(command)
+	if command == "" || m.config.CommandHistorySize <= 0 {
+		return m
+	}
+
+	// The history file is plain text, so keep server credentials out of it
+	if verb, arg, _ := strings.Cut(command, " "); verb == "connect" && config.RedactAddress(arg) != arg {
+		return m
+	}
+
+	// Don't record the same command twice in a row
+	if n := len(m.commandHistory); n > 0 && m.commandHistory[n-1] == command {
+		return m
+	}
+
+	m.commandHistory = append(m.commandHistory, command)
+	if excess := len(m.commandHistory) - m.config.CommandHistorySize; excess > 0 {
+		m.commandHistory = m.commandHistory[excess:]
+	}
+	saveHistory(m.commandHistory)
+	return m
+}
+
+// recallHistory moves through the command history by delta and loads the entry
+// into the command bar. Moving past the newest entry clears the input
+func (m Model) recallHistory(delta int) Model {
+	index := m.historyIndex + delta
+	if index < 0 || index > len(m.commandHistory) {
+		return m
+	}
+
+	m.historyIndex = index
+	if index == len(m.commandHistory) {
+		m.commandInput = ""
+	} else {
+		m.commandInput = m.commandHistory[index]
+	}
+	return m
+}
@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/eallender/nats-ls/internal/config"
+)
+
+// keyBinding is the set of keys triggering an action
+type keyBinding []string
+
+// matches reports whether key triggers the action
+func (b keyBinding) matches(key string) bool {
+	return slices.Contains(b, key)
+}
+
+// KeyMap holds the keys for each configurable action
+type KeyMap struct {
+	Quit         keyBinding
+	NavigateUp   keyBinding
+	NavigateDown keyBinding
+	DrillDown    keyBinding
+	GoBack       keyBinding
+	Command      keyBinding
+	Logs         keyBinding
+	Filter       keyBinding
+}
+
+// NewKeyMap builds a key map from the configured bindings, using the default
+// keys for any action left unbound
+func NewKeyMap(bindings config.KeyBindings) KeyMap {
+	defaults := config.DefaultKeyBindings()
+	bind := func(keys, fallback []string) keyBinding {
+		if len(keys) == 0 {
+			return fallback
+		}
+		return keys
+	}
+
+	return KeyMap{
+		Quit:         bind(bindings.Quit, defaults.Quit),
+		NavigateUp:   bind(bindings.NavigateUp, defaults.NavigateUp),
+		NavigateDown: bind(bindings.NavigateDown, defaults.NavigateDown),
+		DrillDown:    bind(bindings.DrillDown, defaults.DrillDown),
+		GoBack:       bind(bindings.GoBack, defaults.GoBack),
+		Command:      bind(bindings.Command, defaults.Command),
+		Logs:         bind(bindings.Logs, defaults.Logs),
+		Filter:       bind(bindings.Filter, defaults.Filter),
+	}
+}
+
+// arrowKeys shortens arrow key names in key hints
+var arrowKeys = map[string]string{"up": "↑", "down": "↓", "left": "←", "right": "→"}
+
+// keyHint renders the first key of each binding for the header controls, e.g. "<↑↓>"
+func keyHint(bindings ...keyBinding) string {
+	var hint strings.Builder
+	for _, binding := range bindings {
+		if len(binding) == 0 {
+			continue
+		}
+		if arrow, ok := arrowKeys[binding[0]]; ok {
+			hint.WriteString(arrow)
+		} else {
+			hint.WriteString(binding[0])
+		}
+	}
+	return "<" + hint.String() + ">"
+}
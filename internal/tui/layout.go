@@ -10,6 +10,20 @@ const (
 	// Minimum terminal dimensions
 	MinTerminalWidth = 80
 	MinContentHeight = 5
+
+	// Maximum length of a connection error shown in the header
+	maxHeaderErrorLen = 40
+
+	// Maximum width of the extracted value column in the message list
+	maxExtractWidth = 24
+
+	// Maximum width of a size histogram bar in the message detail
+	maxHistogramBarWidth = 40
+
+	// The subject table only shows payload previews when at least this wide
+	minPreviewTableWidth = 120
+	// Maximum width of the payload preview column
+	maxPreviewWidth = 48
 )
 
 // Layout provides helpers for responsive TUI layout calculations
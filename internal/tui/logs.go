@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/eallender/nats-ls/internal/logger"
+)
+
+// Logs view limits
+const (
+	// maxLogLines is the number of trailing log lines kept for the logs view
+	maxLogLines = 500
+	// maxLogTailBytes bounds how much of the end of the log file is read
+	maxLogTailBytes = 256 * 1024
+	// connectionHistoryRows is the number of connection changes shown above the logs
+	connectionHistoryRows = 5
+)
+
+// readLogTail returns up to maxLines of the most recent lines in the file at path
+func readLogTail(path string, maxLines int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// Only read the end of the file, it may be several megabytes
+	offset := info.Size() - maxLogTailBytes
+	if offset < 0 {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if offset > 0 && len(lines) > 0 {
+		// The first line was likely cut part way through
+		lines = lines[1:]
+	}
+	if len(lines) > maxLines {
+		lines = lines[len(lines)-maxLines:]
+	}
+	return lines, nil
+}
+
+// errNoLogFile is shown in the logs view when logs only go to stderr
+var errNoLogFile = errors.New("logs are written to stderr, not a file (see log_output)")
+
+// loadLogs refreshes the lines shown in the logs view
+func (m Model) loadLogs() Model {
+	if logger.LogPath() == "" {
+		m.logLines = nil
+		m.logErr = errNoLogFile
+		return m
+	}
+	lines, err := readLogTail(logger.LogPath(), maxLogLines)
+	m.logLines = lines
+	m.logErr = err
+	return m
+}
@@ -0,0 +1,19 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/eallender/nats-ls/internal/logger"
+)
+
+// TestMain discards logs, which would otherwise go to a nil logger
+func TestMain(m *testing.M) {
+	logger.Log = slog.New(slog.NewTextHandler(io.Discard, nil))
+	os.Exit(m.Run())
+}
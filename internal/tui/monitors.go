@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import (
+	"context"
+	"time"
+
+	"github.com/eallender/nats-ls/internal/config"
+	"github.com/eallender/nats-ls/internal/logger"
+	"github.com/eallender/nats-ls/internal/monitor"
+	"github.com/nats-io/nats.go"
+)
+
+// jetStreamRefreshInterval is how often JetStream streams are re-listed
+const jetStreamRefreshInterval = 5 * time.Second
+
+// startMonitors creates the monitors for a connection and starts discovery.
+// The discovery and JetStream monitors are nil unless enabled in the config
+func startMonitors(nc *nats.Conn, cfg *config.Config) (*monitor.Viewer, *monitor.Discovery, *monitor.JetStream) {
+	viewer := monitor.NewViewer(nc, cfg.NatsViewerMessageLimit)
+	ctx := context.Background()
+
+	// Start discovery to listen for all subjects, unless running viewer-only
+	var discovery *monitor.Discovery
+	if cfg.NatsDiscoveryEnabled {
+		discovery = monitor.NewDiscovery(nc, cfg.NatsDiscoveryRateWindowSeconds, cfg.NatsDiscoveryHistorySeconds, cfg.NatsDiscoveryMaxSubjects, cfg.NatsDiscoveryPreviewBytes, cfg.NatsDiscoveryStaleTTL(), cfg.NatsDiscoveryIgnorePrefixes)
+		if err := discovery.Start(ctx, cfg.NatsDiscoveryPendingLimit, cfg.NatsDiscoveryStorageLimitMB, cfg.NatsDiscoveryQueueGroup); err != nil {
+			logger.Log.Warn("Failed to start discovery", "error", err)
+		}
+	} else {
+		logger.Log.Info("Discovery disabled, running viewer-only")
+	}
+
+	var js *monitor.JetStream
+	if cfg.NatsJetStreamEnabled {
+		var err error
+		js, err = monitor.NewJetStream(nc)
+		if err != nil {
+			logger.Log.Warn("Failed to create JetStream monitor", "error", err)
+		} else {
+			js.Start(ctx, jetStreamRefreshInterval)
+		}
+	}
+
+	return viewer, discovery, js
+}
@@ -4,41 +4,134 @@
 package tui
 
 import (
+	"fmt"
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/eallender/nats-ls/internal/monitor"
 )
 
 // SubjectNode represents a subject or subject prefix in the hierarchy
 type SubjectNode struct {
-	Name         string
-	IsLeaf       bool // true if this is a complete subject, false if it's a prefix
-	MessageCount int64
-	LastSeen     time.Time
-	FirstSeen    time.Time
+	Name            string
+	IsLeaf          bool // true if this is a complete subject, false if it's a prefix of longer subjects
+	MessageCount    int64
+	DirectCount     int64   // Messages on exactly the subject named by this token
+	DescendantCount int64   // Messages on subjects below this token
+	Rate            float64 // Messages per second over the configured rate window
+	TotalBytes      int64
+	HasReplyTo      bool                // true if any subject under this node was used for request/reply
+	ContentType     monitor.ContentType // Guessed payload format, only set for leaves
+	Preview         string              // Start of the latest payload, only set for leaves
+	Stream          string              // JetStream stream capturing this node's subjects, if any
+	Collapsed       bool                // Prefix at the depth limit, standing in for every subject below it
+	LastSeen        time.Time
+	FirstSeen       time.Time
 }
 
-// getSubjectsAtCurrentLevel returns the subjects/prefixes at the current navigation level
-func (m Model) getSubjectsAtCurrentLevel() []SubjectNode {
+// subjectSources returns the subjects and streams to display, frozen while paused
+func (m Model) subjectSources() ([]monitor.SubjectSnapshot, []monitor.Stream) {
+	if m.paused {
+		return m.pausedSubjects, m.pausedStreams
+	}
+	return m.liveSubjectSources()
+}
+
+// liveSubjectSources takes a fresh snapshot of the discovered subjects and streams
+func (m Model) liveSubjectSources() ([]monitor.SubjectSnapshot, []monitor.Stream) {
+	var subjects []monitor.SubjectSnapshot
+	if m.discovery != nil {
+		subjects = m.discovery.Snapshot()
+	}
+
+	var streams []monitor.Stream
+	if m.jetstream != nil {
+		streams = m.jetstream.GetStreams()
+	}
+
+	return subjects, streams
+}
+
+// visibleSubjectNames returns the full names of all subjects the tree could show,
+// honoring the active filter and ignored subjects
+func (m Model) visibleSubjectNames() []string {
 	if m.discovery == nil {
 		return nil
 	}
 
-	subjects := m.discovery.GetAllSubjects()
-	if len(subjects) == 0 {
+	subjects, streams := m.subjectSources()
+
+	seen := make(map[string]bool)
+	var names []string
+	add := func(name string) {
+		if seen[name] {
+			return
+		}
+		if m.filter != "" && !monitor.MatchSubject(m.filter, name, m.separator()) {
+			return
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	for _, subject := range subjects {
+		if !m.discovery.ShowIgnored() && m.discovery.IsIgnored(subject.Name) {
+			continue
+		}
+		add(subject.Name)
+	}
+	for _, stream := range streams {
+		for _, subject := range stream.Subjects {
+			add(subject)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// togglePause freezes the subject tree at its current state, or resumes live updates
+func (m Model) togglePause() Model {
+	m.paused = !m.paused
+	if m.paused {
+		m.pausedSubjects, m.pausedStreams = m.liveSubjectSources()
+	} else {
+		m.pausedSubjects, m.pausedStreams = nil, nil
+	}
+	return m
+}
+
+// getSubjectsAtCurrentLevel returns the subjects/prefixes at the current navigation level
+func (m Model) getSubjectsAtCurrentLevel() []SubjectNode {
+	if m.discovery == nil {
 		return nil
 	}
 
+	subjects, streams := m.subjectSources()
+
 	// Build the current prefix from navPath
-	currentPrefix := strings.Join(m.navPath, ".")
+	sep := m.separator()
+	currentPrefix := strings.Join(m.navPath, sep)
 	if currentPrefix != "" {
-		currentPrefix += "."
+		currentPrefix += sep
 	}
 
-	// Group subjects by the next level
+	// Group subjects by the next level. A token that is both a complete subject and a
+	// prefix of longer subjects gets separate leaf and prefix nodes so counts aren't mixed
 	nodeMap := make(map[string]*SubjectNode)
 
 	for _, subject := range subjects {
+		// Hide ignored subjects recorded while they were toggled on
+		if !m.discovery.ShowIgnored() && m.discovery.IsIgnored(subject.Name) {
+			continue
+		}
+
+		// Skip subjects excluded by the active filter
+		if m.filter != "" && !monitor.MatchSubject(m.filter, subject.Name, m.separator()) {
+			continue
+		}
+
 		// Skip subjects that don't match our current prefix
 		if currentPrefix != "" && !strings.HasPrefix(subject.Name, currentPrefix) {
 			continue
@@ -47,21 +140,22 @@ func (m Model) getSubjectsAtCurrentLevel() []SubjectNode {
 		// Get the part after the current prefix
 		remainder := strings.TrimPrefix(subject.Name, currentPrefix)
 
-		// Split by "." to get the next level
-		parts := strings.Split(remainder, ".")
+		// Split by the separator to get the next level
+		parts := strings.Split(remainder, sep)
 
 		if len(parts) > 0 && parts[0] != "" {
 			nextLevel := parts[0]
 			isLeaf := len(parts) == 1
 
-			lastSeen := subject.LastSeen.Load().(time.Time)
+			lastSeen := subject.LastSeen
 
-			if existing, ok := nodeMap[nextLevel]; ok {
+			if existing, ok := nodeMap[nodeKey(nextLevel, isLeaf)]; ok {
 				// Aggregate message counts
-				existing.MessageCount += subject.MessageCount.Load()
-				// If any subject is a leaf, mark it as such
-				if isLeaf {
-					existing.IsLeaf = true
+				existing.MessageCount += subject.MessageCount
+				existing.Rate += subject.Rate
+				existing.TotalBytes += subject.TotalBytes
+				if subject.HasReplyTo {
+					existing.HasReplyTo = true
 				}
 				// Track the most recent LastSeen
 				if lastSeen.After(existing.LastSeen) {
@@ -72,27 +166,157 @@ func (m Model) getSubjectsAtCurrentLevel() []SubjectNode {
 					existing.FirstSeen = subject.FirstSeen
 				}
 			} else {
-				nodeMap[nextLevel] = &SubjectNode{
+				node := &SubjectNode{
 					Name:         nextLevel,
 					IsLeaf:       isLeaf,
-					MessageCount: subject.MessageCount.Load(),
+					MessageCount: subject.MessageCount,
+					Rate:         subject.Rate,
+					TotalBytes:   subject.TotalBytes,
+					HasReplyTo:   subject.HasReplyTo,
 					LastSeen:     lastSeen,
 					FirstSeen:    subject.FirstSeen,
 				}
+				// Prefixes mix many subjects, so only leaves carry a content type
+				if isLeaf {
+					node.ContentType = subject.ContentType
+					node.Preview = subject.Preview
+				}
+				nodeMap[nodeKey(nextLevel, isLeaf)] = node
 			}
 		}
 	}
 
-	// Convert map to slice
+	// Merge in JetStream stream subjects so idle streams are still navigable
+	m.mergeStreamSubjects(nodeMap, streams, currentPrefix)
+
+	// A token's leaf and prefix nodes each know one half of its counts, so share them
+	for _, node := range nodeMap {
+		if !node.IsLeaf {
+			continue
+		}
+		node.DirectCount = node.MessageCount
+		if prefix, ok := nodeMap[nodeKey(node.Name, false)]; ok {
+			node.DescendantCount = prefix.MessageCount
+			prefix.DirectCount = node.MessageCount
+		}
+	}
+	for _, node := range nodeMap {
+		if !node.IsLeaf {
+			node.DescendantCount = node.MessageCount
+		}
+	}
+
+	// Convert map to slice, collapsing prefixes that would go past the depth limit. A
+	// prefix's LastSeen is its most recent subject's, so it stays while any is active
+	collapse := m.atMaxDepth()
+	activeSince := m.activeSince()
 	var nodes []SubjectNode
 	for _, node := range nodeMap {
+		if node.LastSeen.Before(activeSince) {
+			continue
+		}
+		node.Collapsed = collapse && !node.IsLeaf
 		nodes = append(nodes, *node)
 	}
 
-	// Sort alphabetically to maintain consistent order
+	// Sort alphabetically to maintain consistent order, listing a leaf before its prefix
 	sort.Slice(nodes, func(i, j int) bool {
-		return nodes[i].Name < nodes[j].Name
+		if nodes[i].Name != nodes[j].Name {
+			return nodes[i].Name < nodes[j].Name
+		}
+		return nodes[i].IsLeaf
 	})
 
 	return nodes
 }
+
+// prefixWatchUnsupported explains why a prefix can't be watched with a custom separator
+const prefixWatchUnsupported = "Prefixes can only be watched when subjects are separated by \".\""
+
+// separator returns the character the subject tree splits subjects on
+func (m Model) separator() string {
+	if m.config == nil || m.config.NatsSubjectSeparator == "" {
+		return "."
+	}
+	return m.config.NatsSubjectSeparator
+}
+
+// activeSince returns the time subjects must have been seen after to be shown,
+// zero unless only showing active subjects
+func (m Model) activeSince() time.Time {
+	if !m.activeOnly || m.config == nil {
+		return time.Time{}
+	}
+	return time.Now().Add(-m.config.ActiveWindow())
+}
+
+// activeLabel labels the header while only showing active subjects, e.g. "ACTIVE <60s"
+func (m Model) activeLabel() string {
+	return fmt.Sprintf("ACTIVE <%ds", m.config.ActiveWindowSeconds)
+}
+
+// maxDepth returns how many tokens deep the tree goes before collapsing, 0 for unlimited
+func (m Model) maxDepth() int {
+	if m.config == nil {
+		return 0
+	}
+	return max(m.config.NatsDiscoveryMaxDepth, 0)
+}
+
+// atMaxDepth reports whether nodes at the current level are at the depth limit
+func (m Model) atMaxDepth() bool {
+	return m.maxDepth() > 0 && len(m.navPath)+1 >= m.maxDepth()
+}
+
+// mergeStreamSubjects adds the subjects captured by JetStream streams at the current level
+func (m Model) mergeStreamSubjects(nodeMap map[string]*SubjectNode, streams []monitor.Stream, currentPrefix string) {
+	for _, stream := range streams {
+		for _, subject := range stream.Subjects {
+			if m.filter != "" && !monitor.MatchSubject(m.filter, subject, m.separator()) {
+				continue
+			}
+			if currentPrefix != "" && !strings.HasPrefix(subject, currentPrefix) {
+				continue
+			}
+
+			parts := strings.Split(strings.TrimPrefix(subject, currentPrefix), m.separator())
+			if parts[0] == "" {
+				continue
+			}
+			nextLevel := parts[0]
+			isLeaf := len(parts) == 1
+
+			if existing, ok := nodeMap[nodeKey(nextLevel, isLeaf)]; ok {
+				existing.Stream = stream.Name
+				if stream.LastSeen.After(existing.LastSeen) {
+					existing.LastSeen = stream.LastSeen
+				}
+			} else {
+				nodeMap[nodeKey(nextLevel, isLeaf)] = &SubjectNode{
+					Name:     nextLevel,
+					IsLeaf:   isLeaf,
+					Stream:   stream.Name,
+					LastSeen: stream.LastSeen,
+				}
+			}
+		}
+	}
+}
+
+// nodeKey identifies a node at the current level, keeping a complete subject apart
+// from the prefix of longer subjects sharing its name
+func nodeKey(name string, isLeaf bool) string {
+	if isLeaf {
+		return name
+	}
+	return name + ".>"
+}
+
+// FullName returns the node's full subject under navPath joined by sep, ending in ">" for prefixes
+func (n SubjectNode) FullName(navPath []string, sep string) string {
+	tokens := append(append([]string{}, navPath...), n.Name)
+	if !n.IsLeaf {
+		tokens = append(tokens, ">")
+	}
+	return strings.Join(tokens, sep)
+}
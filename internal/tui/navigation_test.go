@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eallender/nats-ls/internal/config"
+	"github.com/eallender/nats-ls/internal/monitor"
+)
+
+// pausedModel returns a model showing the given subjects as if paused on them, so the
+// tree can be navigated without a connection
+func pausedModel(cfg *config.Config, subjects ...monitor.SubjectSnapshot) Model {
+	now := time.Now()
+	for i := range subjects {
+		subjects[i].FirstSeen = now
+		subjects[i].LastSeen = now
+	}
+	return Model{
+		config:         cfg,
+		discovery:      monitor.NewDiscovery(nil, 10, 10, 0, 0, 0, nil),
+		paused:         true,
+		pausedSubjects: subjects,
+	}
+}
+
+func TestSubjectsAtCurrentLevelSeparator(t *testing.T) {
+	m := pausedModel(&config.Config{NatsSubjectSeparator: "/"},
+		monitor.SubjectSnapshot{Name: "orders/new.us", MessageCount: 1},
+		monitor.SubjectSnapshot{Name: "orders/paid", MessageCount: 2},
+	)
+	m.filter = "orders/*"
+	m.navPath = []string{"orders"}
+
+	nodes := m.getSubjectsAtCurrentLevel()
+	if len(nodes) != 2 {
+		t.Fatalf("got %d nodes, want 2: %+v", len(nodes), nodes)
+	}
+	if nodes[0].Name != "new.us" || !nodes[0].IsLeaf {
+		t.Errorf("nodes[0] = %+v, want leaf new.us", nodes[0])
+	}
+	if nodes[1].Name != "paid" || nodes[1].MessageCount != 2 {
+		t.Errorf("nodes[1] = %+v, want paid with 2 messages", nodes[1])
+	}
+}
+
+func TestSubjectsAtCurrentLevelMixedCounts(t *testing.T) {
+	m := pausedModel(&config.Config{},
+		monitor.SubjectSnapshot{Name: "orders", MessageCount: 2},
+		monitor.SubjectSnapshot{Name: "orders.new", MessageCount: 3},
+		monitor.SubjectSnapshot{Name: "orders.paid", MessageCount: 4},
+	)
+
+	nodes := m.getSubjectsAtCurrentLevel()
+	if len(nodes) != 2 {
+		t.Fatalf("got %d nodes, want leaf and prefix: %+v", len(nodes), nodes)
+	}
+	leaf, prefix := nodes[0], nodes[1]
+	if !leaf.IsLeaf || leaf.MessageCount != 2 || leaf.DirectCount != 2 || leaf.DescendantCount != 7 {
+		t.Errorf("leaf = %+v, want 2 messages, 2 direct, 7 below", leaf)
+	}
+	if prefix.IsLeaf || prefix.MessageCount != 7 || prefix.DirectCount != 2 || prefix.DescendantCount != 7 {
+		t.Errorf("prefix = %+v, want 7 messages, 2 direct, 7 below", prefix)
+	}
+}
+
+func TestSubjectsAtCurrentLevelNested(t *testing.T) {
+	m := pausedModel(&config.Config{},
+		monitor.SubjectSnapshot{Name: "orders", MessageCount: 1},
+		monitor.SubjectSnapshot{Name: "orders.new", MessageCount: 2},
+		monitor.SubjectSnapshot{Name: "orders.new.us", MessageCount: 4},
+	)
+
+	tests := []struct {
+		navPath []string
+		want    []SubjectNode
+	}{
+		{nil, []SubjectNode{
+			{Name: "orders", IsLeaf: true, MessageCount: 1},
+			{Name: "orders", IsLeaf: false, MessageCount: 6},
+		}},
+		{[]string{"orders"}, []SubjectNode{
+			{Name: "new", IsLeaf: true, MessageCount: 2},
+			{Name: "new", IsLeaf: false, MessageCount: 4},
+		}},
+		{[]string{"orders", "new"}, []SubjectNode{
+			{Name: "us", IsLeaf: true, MessageCount: 4},
+		}},
+	}
+	for _, tt := range tests {
+		m.navPath = tt.navPath
+		nodes := m.getSubjectsAtCurrentLevel()
+		if len(nodes) != len(tt.want) {
+			t.Errorf("at %v got %d nodes, want %d: %+v", tt.navPath, len(nodes), len(tt.want), nodes)
+			continue
+		}
+		for i, want := range tt.want {
+			got := nodes[i]
+			if got.Name != want.Name || got.IsLeaf != want.IsLeaf || got.MessageCount != want.MessageCount {
+				t.Errorf("at %v node %d = %s leaf %v count %d, want %s leaf %v count %d",
+					tt.navPath, i, got.Name, got.IsLeaf, got.MessageCount, want.Name, want.IsLeaf, want.MessageCount)
+			}
+		}
+	}
+}
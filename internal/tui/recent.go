@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import (
+	"sort"
+
+	"github.com/eallender/nats-ls/internal/monitor"
+)
+
+// recentSubjectsLimit is the most subjects listed in the recently changed view
+const recentSubjectsLimit = 100
+
+// recentSubjects returns the subjects seen most recently across the whole tree, newest
+// first, honoring the active filter and ignored subjects
+func (m Model) recentSubjects() []monitor.SubjectSnapshot {
+	if m.discovery == nil {
+		return nil
+	}
+
+	subjects, _ := m.subjectSources()
+	var recent []monitor.SubjectSnapshot
+	for _, subject := range subjects {
+		if !m.discovery.ShowIgnored() && m.discovery.IsIgnored(subject.Name) {
+			continue
+		}
+		if m.filter != "" && !monitor.MatchSubject(m.filter, subject.Name, m.separator()) {
+			continue
+		}
+		recent = append(recent, subject)
+	}
+
+	sort.Slice(recent, func(i, j int) bool {
+		if !recent[i].LastSeen.Equal(recent[j].LastSeen) {
+			return recent[i].LastSeen.After(recent[j].LastSeen)
+		}
+		return recent[i].Name < recent[j].Name
+	})
+	return recent[:min(len(recent), recentSubjectsLimit)]
+}
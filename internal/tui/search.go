@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// searchResult is a subject matching the search query
+type searchResult struct {
+	Subject   string
+	Positions []int // Byte offsets of the matched characters in Subject
+	score     int
+}
+
+// fuzzyMatch reports whether all characters of query appear in target in order,
+// ignoring case. It returns the matched positions and a score where lower is better
+func fuzzyMatch(query, target string) ([]int, int, bool) {
+	if query == "" {
+		return nil, 0, true
+	}
+
+	queryRunes := []rune(strings.ToLower(query))
+	positions := make([]int, 0, len(queryRunes))
+	score := 0
+	last := -1
+
+	qi := 0
+	for i, r := range target {
+		if qi == len(queryRunes) {
+			break
+		}
+		if unicode.ToLower(r) != queryRunes[qi] {
+			continue
+		}
+		// Penalize gaps between matched characters so tighter matches rank first
+		if last >= 0 {
+			score += i - last - 1
+		} else {
+			score += i
+		}
+		positions = append(positions, i)
+		last = i
+		qi++
+	}
+
+	if qi < len(queryRunes) {
+		return nil, 0, false
+	}
+	return positions, score, true
+}
+
+// searchSubjects returns the subjects fuzzy matching query, best matches first
+func searchSubjects(query string, subjects []string) []searchResult {
+	var results []searchResult
+	for _, subject := range subjects {
+		if positions, score, ok := fuzzyMatch(query, subject); ok {
+			results = append(results, searchResult{Subject: subject, Positions: positions, score: score})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score < results[j].score
+		}
+		return results[i].Subject < results[j].Subject
+	})
+
+	return results
+}
+
+// filterMatchPositions returns the byte offsets in name, the subject token at depth, matched
+// by a literal token of the wildcard filter pattern split on sep. Wildcards match without
+// naming anything, so they aren't highlighted
+func filterMatchPositions(pattern, sep string, depth int, name string) []int {
+	tokens := strings.Split(pattern, sep)
+	if depth >= len(tokens) || tokens[depth] != name {
+		return nil
+	}
+
+	positions := make([]int, 0, len(name))
+	for i := range name {
+		positions = append(positions, i)
+	}
+	return positions
+}
+
+// searchResults returns the matches for the current search query
+func (m Model) searchResults() []searchResult {
+	return searchSubjects(m.commandInput, m.visibleSubjectNames())
+}
+
+// jumpToSubject navigates the subject tree to the parent of subject and selects it
+func (m Model) jumpToSubject(subject string) Model {
+	tokens := strings.Split(subject, m.separator())
+
+	// Subjects past the depth limit live under a collapsed prefix node
+	isLeaf := true
+	if depth := m.maxDepth(); depth > 0 && len(tokens) > depth {
+		tokens = tokens[:depth]
+		isLeaf = false
+	}
+
+	m.navPath = append([]string{}, tokens[:len(tokens)-1]...)
+	m.selectedIndex = 0
+
+	for i, node := range m.getSubjectsAtCurrentLevel() {
+		if node.IsLeaf == isLeaf && node.Name == tokens[len(tokens)-1] {
+			m.selectedIndex = i
+			break
+		}
+	}
+	return m
+}
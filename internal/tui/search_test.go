@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestFilterMatchPositionsSeparator(t *testing.T) {
+	tests := []struct {
+		pattern string
+		sep     string
+		depth   int
+		name    string
+		want    []int
+	}{
+		{"orders.new", ".", 1, "new", []int{0, 1, 2}},
+		{"orders/new", "/", 1, "new", []int{0, 1, 2}},
+		{"orders/new", "/", 0, "orders", []int{0, 1, 2, 3, 4, 5}},
+		{"orders/*", "/", 1, "new", nil},
+		{"orders.new", "/", 1, "new", nil},
+	}
+	for _, tt := range tests {
+		if got := filterMatchPositions(tt.pattern, tt.sep, tt.depth, tt.name); !slices.Equal(got, tt.want) {
+			t.Errorf("filterMatchPositions(%q, %q, %d, %q) = %v, want %v", tt.pattern, tt.sep, tt.depth, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFilterMatchPositions(t *testing.T) {
+	tests := []struct {
+		pattern string
+		depth   int
+		name    string
+		want    []int
+	}{
+		{"orders.*", 0, "orders", []int{0, 1, 2, 3, 4, 5}},
+		{"orders.*", 1, "new", nil},
+		{"orders.>", 2, "us", nil},
+		{"orders", 1, "new", nil},
+		{"orders.new", 0, "order", nil},
+		// Offsets are bytes, so a multi-byte token skips continuation bytes
+		{"注文.*", 0, "注文", []int{0, 3}},
+	}
+	for _, tt := range tests {
+		if got := filterMatchPositions(tt.pattern, ".", tt.depth, tt.name); !slices.Equal(got, tt.want) {
+			t.Errorf("filterMatchPositions(%q, %d, %q) = %v, want %v", tt.pattern, tt.depth, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	// Mark segments by their style so the split shows without terminal colors
+	rowStyle := lipgloss.NewStyle()
+	matchStyle := lipgloss.NewStyle().Transform(func(s string) string { return "[" + s + "]" })
+
+	tests := []struct {
+		text      string
+		positions []int
+		want      string
+	}{
+		{"orders.new", nil, "orders.new"},
+		{"orders.new", []int{7, 8, 9}, "orders.[new]"},
+		{"orders.new", []int{0, 1, 2, 3, 4, 5}, "[orders].new"},
+		{"orders.new", []int{0, 9}, "[o]rders.ne[w]"},
+		{"注文.新規", []int{0, 3}, "[注文].新規"},
+		{"注文.新規", []int{7}, "注文.[新]規"},
+	}
+	for _, tt := range tests {
+		if got := highlightMatches(tt.text, tt.positions, rowStyle, matchStyle); got != tt.want {
+			t.Errorf("highlightMatches(%q, %v) = %q, want %q", tt.text, tt.positions, got, tt.want)
+		}
+	}
+}
@@ -22,80 +22,162 @@ var (
 	ColorBackground = lipgloss.Color("235") // dark gray
 )
 
-// Header styles
+// Styles built from the color palette by buildStyles
 var (
+	HeaderContainerStyle     lipgloss.Style
+	HeaderAppNameStyle       lipgloss.Style
+	HeaderConnectedStyle     lipgloss.Style
+	HeaderDisconnectedStyle  lipgloss.Style
+	HeaderServerStyle        lipgloss.Style
+	HeaderStatsStyle         lipgloss.Style
+	HeaderDividerStyle       lipgloss.Style
+	HeaderControlStyle       lipgloss.Style
+	HeaderControlStyleInfo   lipgloss.Style
+	HeaderStatusInfoStyle    lipgloss.Style
+	HeaderFilterStyle        lipgloss.Style
+	HeaderWarningStyle       lipgloss.Style
+	NavStyle                 lipgloss.Style
+	NavTableHeaderStyle      lipgloss.Style
+	NavTableRowStyle         lipgloss.Style
+	NavTableSelectedRowStyle lipgloss.Style
+	DuplicateRowStyle        lipgloss.Style
+	LogErrorStyle            lipgloss.Style
+	LogWarnStyle             lipgloss.Style
+	LogDebugStyle            lipgloss.Style
+	SearchMatchStyle         lipgloss.Style
+	SearchMatchSelectedStyle lipgloss.Style
+	DetailLabelStyle         lipgloss.Style
+	HistogramBarStyle        lipgloss.Style
+	InfoStyle                lipgloss.Style
+	CommandBarStyle          lipgloss.Style
+	CompletionStyle          lipgloss.Style
+	CompletionSelectedStyle  lipgloss.Style
+)
+
+func init() {
+	buildStyles()
+}
+
+// buildStyles derives every style from the current color palette
+func buildStyles() {
+	// Header styles
 	HeaderContainerStyle = lipgloss.NewStyle().
-				BorderStyle(lipgloss.NormalBorder()).
-				BorderBottom(true)
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderBottom(true)
 
 	HeaderAppNameStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(ColorPrimary).
-				Padding(0, 1).
-				MarginRight(2)
+		Bold(true).
+		Foreground(ColorPrimary).
+		Padding(0, 1).
+		MarginRight(2)
 
 	HeaderConnectedStyle = lipgloss.NewStyle().
-				Foreground(ColorSuccess).
-				Padding(0, 1)
+		Foreground(ColorSuccess).
+		Padding(0, 1)
 
 	HeaderDisconnectedStyle = lipgloss.NewStyle().
-				Foreground(ColorError).
-				Padding(0, 1)
+		Foreground(ColorError).
+		Padding(0, 1)
 
 	HeaderServerStyle = lipgloss.NewStyle().
-				Foreground(ColorMuted).
-				Padding(0, 1)
+		Foreground(ColorMuted).
+		Padding(0, 1)
 
 	HeaderStatsStyle = lipgloss.NewStyle().
-				Foreground(ColorMuted).
-				Padding(0, 1)
+		Foreground(ColorMuted).
+		Padding(0, 1)
 
 	HeaderDividerStyle = lipgloss.NewStyle().
-				Foreground(ColorMuted)
+		Foreground(ColorMuted)
 
 	HeaderControlStyle = lipgloss.NewStyle().
-				Foreground(ColorInfo).
-				MarginRight(1)
+		Foreground(ColorInfo).
+		MarginRight(1)
 
 	HeaderControlStyleInfo = lipgloss.NewStyle().
-				Foreground(ColorMuted)
+		Foreground(ColorMuted)
 
 	HeaderStatusInfoStyle = lipgloss.NewStyle().
-				MarginRight(6)
-)
+		MarginRight(6)
 
-// Navigation styles
-var (
+	HeaderFilterStyle = lipgloss.NewStyle().
+		Foreground(ColorWarning).
+		Padding(0, 1)
+
+	HeaderWarningStyle = lipgloss.NewStyle().
+		Foreground(ColorWarning).
+		Bold(true).
+		Padding(0, 1)
+
+	// Navigation styles
 	NavStyle = lipgloss.NewStyle().
 		Padding(1, 2).
 		BorderStyle(lipgloss.NormalBorder()).
 		BorderForeground(ColorMuted)
 
 	NavTableHeaderStyle = lipgloss.NewStyle().
-				Foreground(ColorPrimary).
-				Bold(true)
+		Foreground(ColorPrimary).
+		Bold(true)
 
 	NavTableRowStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("252"))
+		Foreground(lipgloss.Color("252"))
 
 	NavTableSelectedRowStyle = lipgloss.NewStyle().
-					Foreground(lipgloss.Color("0")).
-					Background(ColorPrimary).
-					Bold(true)
-)
+		Foreground(lipgloss.Color("0")).
+		Background(ColorPrimary).
+		Bold(true)
 
-// Info styles
-var (
+	DuplicateRowStyle = lipgloss.NewStyle().
+		Foreground(ColorWarning).
+		Italic(true)
+
+	// Logs styles
+	LogErrorStyle = lipgloss.NewStyle().
+		Foreground(ColorError)
+
+	LogWarnStyle = lipgloss.NewStyle().
+		Foreground(ColorWarning)
+
+	LogDebugStyle = lipgloss.NewStyle().
+		Foreground(ColorMuted)
+
+	// Search styles
+	SearchMatchStyle = lipgloss.NewStyle().
+		Foreground(ColorWarning).
+		Bold(true)
+
+	SearchMatchSelectedStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("0")).
+		Background(ColorPrimary).
+		Bold(true).
+		Underline(true)
+
+	// Message detail styles
+	DetailLabelStyle = lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	HistogramBarStyle = lipgloss.NewStyle().
+		Foreground(ColorInfo)
+
+	// Info styles
 	InfoStyle = lipgloss.NewStyle().
 		Padding(1, 2).
 		BorderStyle(lipgloss.NormalBorder()).
 		BorderForeground(ColorMuted)
-)
 
-// Command bar styles
-var (
+	// Command bar styles
 	CommandBarStyle = lipgloss.NewStyle().
 		Foreground(ColorPrimary).
 		Background(ColorBackground).
 		Padding(0, 1)
-)
+
+	CompletionStyle = lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Background(ColorBackground)
+
+	CompletionSelectedStyle = lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Background(ColorBackground).
+		Bold(true)
+}
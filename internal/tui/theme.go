@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/eallender/nats-ls/internal/config"
+	"github.com/eallender/nats-ls/internal/logger"
+)
+
+// DefaultThemeName is the built-in theme matching the original color palette
+const DefaultThemeName = "default"
+
+// builtinThemes are the themes selectable by name, empty colors keep the default palette
+var builtinThemes = map[string]config.Theme{
+	DefaultThemeName: {},
+	"dracula": {
+		Primary:    "#ff79c6",
+		Success:    "#50fa7b",
+		Error:      "#ff5555",
+		Warning:    "#f1fa8c",
+		Info:       "#bd93f9",
+		Muted:      "#6272a4",
+		Background: "#282a36",
+	},
+	"solarized": {
+		Primary:    "#268bd2",
+		Success:    "#859900",
+		Error:      "#dc322f",
+		Warning:    "#b58900",
+		Info:       "#6c71c4",
+		Muted:      "#586e75",
+		Background: "#073642",
+	},
+}
+
+// hexColorPattern matches #rgb and #rrggbb colors
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// ApplyTheme sets the color palette from the named built-in theme, overridden by any
+// colors set in the theme config, and rebuilds the styles. Unknown theme names and
+// invalid colors fall back to the default palette
+func ApplyTheme(theme config.Theme) {
+	base, ok := builtinThemes[theme.Name]
+	if !ok && theme.Name != "" {
+		logger.Log.Warn("Unknown theme, using default", "theme", theme.Name)
+	}
+
+	applyColor(&ColorPrimary, "primary", base.Primary, theme.Primary)
+	applyColor(&ColorSuccess, "success", base.Success, theme.Success)
+	applyColor(&ColorError, "error", base.Error, theme.Error)
+	applyColor(&ColorWarning, "warning", base.Warning, theme.Warning)
+	applyColor(&ColorInfo, "info", base.Info, theme.Info)
+	applyColor(&ColorMuted, "muted", base.Muted, theme.Muted)
+	applyColor(&ColorBackground, "background", base.Background, theme.Background)
+
+	buildStyles()
+}
+
+// UsePlainStyles renders without color for screen readers, logged output and dumb
+// terminals. Bold and reverse video are kept, since the ASCII color profile would also
+// drop them and selections are otherwise only shown by color
+func UsePlainStyles() {
+	for _, color := range []*lipgloss.Color{&ColorPrimary, &ColorSuccess, &ColorError, &ColorWarning, &ColorInfo, &ColorMuted, &ColorBackground} {
+		*color = ""
+	}
+	buildStyles()
+
+	NavTableRowStyle = NavTableRowStyle.UnsetForeground()
+	NavTableSelectedRowStyle = NavTableSelectedRowStyle.UnsetForeground().Reverse(true)
+	SearchMatchSelectedStyle = SearchMatchSelectedStyle.UnsetForeground().Reverse(true)
+	CompletionSelectedStyle = CompletionSelectedStyle.Reverse(true)
+}
+
+// applyColor sets color to override if valid, otherwise to the theme's value,
+// leaving the default in place when neither is set
+func applyColor(color *lipgloss.Color, name, themed, override string) {
+	for _, value := range []string{override, themed} {
+		if value == "" {
+			continue
+		}
+		if !validColor(value) {
+			logger.Log.Warn("Invalid theme color, ignoring", "color", name, "value", value)
+			continue
+		}
+		*color = lipgloss.Color(value)
+		return
+	}
+}
+
+// validColor reports whether value is a hex color or an ANSI 256 color number
+func validColor(value string) bool {
+	if hexColorPattern.MatchString(value) {
+		return true
+	}
+	n, err := strconv.Atoi(value)
+	return err == nil && n >= 0 && n <= 255
+}
@@ -4,12 +4,12 @@
 package tui
 
 import (
-	"context"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/eallender/nats-ls/internal/config"
 	"github.com/eallender/nats-ls/internal/logger"
+	"github.com/eallender/nats-ls/internal/metrics"
 	"github.com/eallender/nats-ls/internal/monitor"
 	"github.com/nats-io/nats.go"
 )
@@ -20,46 +20,164 @@ type Model struct {
 	height   int
 	quitting bool
 
+	// Input and output
+	keys      KeyMap
+	clipboard Clipboard
+	decoders  *monitor.DecoderRegistry // Payload decoders picked per subject
+
 	// Connection state
 	nc           *nats.Conn
 	serverURL    string
-	messageCount int
+	messageCount int64     // Total messages seen by discovery, refreshed each tick
+	messageRate  float64   // Messages per second across all subjects over the last tick
+	countedAt    time.Time // When messageCount was last refreshed, zero after disconnecting
+	dropped      int       // Messages discovery had dropped as of the last tick
+	lastDropAt   time.Time // Tick at which discovery last dropped messages
+	dropping     bool      // Discovery dropped messages recently, so counts are incomplete
 	config       *config.Config
+	connectErr   error  // Last connection error, shown while disconnected
+	connectTries int    // Failed connection attempts since last connected
+	switchingTo  string // Redacted address given to :connect, reported when its first attempt completes
+	spinnerFrame int    // Frame of the reconnecting spinner, advanced while disconnected
+	spinning     bool   // A spinner tick is scheduled
+	rtt          time.Duration
+	rttErr       error
 
 	// Command bar state
 	commandBarActive bool
 	commandInput     string
+	statusMessage    string // Feedback from the last command, cleared on next key press
+	commandHistory   []string
+	historyIndex     int      // Position in commandHistory while recalling, len(commandHistory) for new input
+	completions      []string // Subject candidates being cycled by tab completion
+	completionBase   string   // Command input before the word being completed
+	completionIndex  int
+
+	// Search state, the query shares the command bar input
+	searchActive bool
+	searchIndex  int
 
 	// Navigation state
 	selectedIndex int
+	pendingCount  int      // Row number typed as a count prefix, applied by the next movement key
 	navPath       []string // Current navigation path for hierarchical subject browsing
+	filter        string   // NATS wildcard pattern restricting the displayed subjects
+	watching      string   // Subject currently being watched by the viewer, empty for the subject tree
+
+	// Only show subjects seen within the configured active window
+	activeOnly bool
+
+	// Paused state, the subject tree renders from a snapshot taken when paused
+	paused         bool
+	pausedSubjects []monitor.SubjectSnapshot
+	pausedStreams  []monitor.Stream
+
+	// Logs view state
+	showLogs  bool
+	logLines  []string
+	logErr    error
+	logScroll int // Lines scrolled up from the newest log line
+
+	// Show connection diagnostics over the subject tree
+	showDiagnostics bool
+
+	// Full name of the subject whose stats are shown over the subject tree, empty when closed
+	subjectDetail       string
+	subjectDetailStream string // JetStream stream capturing the subject, if any
+
+	// Bookmarked subjects, sorted, with prefixes ending in ".>"
+	bookmarks     []string
+	showBookmarks bool
+	bookmarkIndex int // Selected bookmark in the bookmarks list
+
+	// Flat list of the most recently seen subjects across the tree
+	showRecent  bool
+	recentIndex int
+
+	// Show timestamps in the configured layout instead of "2m ago"
+	absoluteTimes bool
+
+	// Waiting for the user to confirm quitting while watching a subject
+	confirmingQuit bool
+
+	// Show the single-line header instead of the full one
+	compactHeader bool
+
+	// Subject given with --watch, watched once the first connection is up
+	startSubject string
+
+	// Message viewer state
+	selectedMessageIndex int              // Selected message while not auto scrolling
+	autoScroll           bool             // Follow the newest message as messages arrive, like less +F
+	detailMessage        *monitor.Message // Message shown in the detail view, nil for the message list
+	prettyPrint          bool             // Decode payloads in the detail view, picked per subject from its content type
+	hexView              bool             // Show the detail payload as a hex dump, reset per message
+	messageFilter        string           // Regex the viewer matches new payloads against, reset per subject
 
 	// NATS management
 	viewer    *monitor.Viewer
 	discovery *monitor.Discovery
+	jetstream *monitor.JetStream // nil unless JetStream is enabled
+	metrics   *metrics.Server    // nil unless a metrics address is configured
+
+	// JetStream consumer view state
+	consumerStream string // Stream whose consumers are shown, empty when not viewing consumers
+	consumers      []monitor.Consumer
+	consumersErr   error
 }
 
 // connectAttemptMsg is sent when a connection attempt completes
 type connectAttemptMsg struct {
+	address   string // Address the attempt was made against
 	nc        *nats.Conn
 	viewer    *monitor.Viewer
 	discovery *monitor.Discovery
+	jetstream *monitor.JetStream
+	err       error
+}
+
+// consumersMsg is sent when a stream's consumers have been fetched
+type consumersMsg struct {
+	stream    string
+	consumers []monitor.Consumer
 	err       error
 }
 
+// rttMsg is sent when a round-trip latency sample completes
+type rttMsg struct {
+	nc  *nats.Conn // Connection the sample was taken on
+	rtt time.Duration
+	err error
+}
+
+// statusMsg is sent by background commands to report a result in the command bar
+type statusMsg string
+
 // tickMsg is sent periodically to refresh the UI and retry connections
 type tickMsg time.Time
 
+// spinnerMsg advances the reconnecting spinner
+type spinnerMsg struct{}
+
 // New creates a new TUI model
-func New(nc *nats.Conn, viewer *monitor.Viewer, discovery *monitor.Discovery, serverURL string, cfg *config.Config) Model {
-	return Model{
-		nc:           nc,
-		serverURL:    serverURL,
-		messageCount: 0,
-		viewer:       viewer,
-		discovery:    discovery,
-		config:       cfg,
+func New(nc *nats.Conn, viewer *monitor.Viewer, discovery *monitor.Discovery, jetstream *monitor.JetStream, serverURL string, cfg *config.Config) Model {
+	m := Model{
+		nc:          nc,
+		serverURL:   serverURL,
+		viewer:      viewer,
+		discovery:   discovery,
+		jetstream:   jetstream,
+		config:      cfg,
+		prettyPrint: true,
+		autoScroll:  true,
+		clipboard:   systemClipboard{},
+		keys:        NewKeyMap(cfg.KeyBindings),
+
+		compactHeader: cfg.CompactHeader,
+		startSubject:  cfg.WatchSubject,
 	}
+	m.decoders = newDecoderRegistry(cfg.PayloadDecoders, m.separator())
+	return m
 }
 
 // Run starts the TUI
@@ -67,56 +185,47 @@ func Run(config *config.Config) error {
 	var nc *nats.Conn
 	var viewer *monitor.Viewer
 	var discovery *monitor.Discovery
+	var jetstream *monitor.JetStream
+
+	ApplyTheme(config.Theme)
+	if config.NoColor {
+		UsePlainStyles()
+	}
 
 	var err error
-	nc, err = nats.Connect(
-		config.NatsAddress,
-		nats.MaxReconnects(config.NatsMaxReconnects),
-		nats.ReconnectWait(time.Duration(config.NatsReconnectWaitSeconds)*time.Second),
-		nats.DisconnectErrHandler(func(nc *nats.Conn, err error) {
-			if err != nil {
-				logger.Log.Warn("Disconnected from NATS", "error", err)
-			} else {
-				logger.Log.Info("Disconnected from NATS")
-			}
-		}),
-		nats.ReconnectHandler(func(nc *nats.Conn) {
-			logger.Log.Info("Reconnected to NATS", "address", nc.ConnectedUrl())
-		}),
-		nats.ClosedHandler(func(nc *nats.Conn) {
-			logger.Log.Debug("NATS connection closed")
-		}),
-	)
+	nc, err = monitor.Connect(config)
 	if err != nil {
 		// Initial connection failed, but continue with TUI
-		logger.Log.Warn("Could not connect to NATS", "address", config.NatsAddress, "error", err)
+		logger.Log.Warn("Could not connect to NATS", "address", config.RedactedAddress(), "error", err)
 	} else {
-		viewer = monitor.NewViewer(nc, config.NatsViewerMessageLimit)
-		discovery = monitor.NewDiscovery(nc)
+		viewer, discovery, jetstream = startMonitors(nc, config)
 
-		// Start discovery to listen for all subjects
-		ctx := context.Background()
-		if err := discovery.Start(ctx, config.NatsDiscoveryPendingLimit, config.NatsDiscoveryStorageLimitMB); err != nil {
-			logger.Log.Warn("Failed to start discovery", "error", err)
-		}
+		logger.Log.Info("Connected to NATS", "address", config.RedactedAddress())
+	}
 
-		logger.Log.Info("Connected to NATS", "address", config.NatsAddress)
+	model := New(nc, viewer, discovery, jetstream, config.RedactedAddress(), config)
+	model.connectErr = err
+	if err != nil {
+		model.connectTries = 1
+	}
+	model.commandHistory = loadHistory()
+	model.bookmarks = loadBookmarks()
+	model = model.watchStartSubject()
+
+	// Serve metrics alongside the TUI until it exits
+	if config.MetricsAddr != "" {
+		model.metrics = metrics.NewServer(config.MetricsAddr)
+		model.metrics.SetSource(nc, discovery)
+		model.metrics.Start()
+		defer model.metrics.Stop()
 	}
 
-	p := tea.NewProgram(New(nc, viewer, discovery, config.NatsAddress, config), tea.WithAltScreen())
+	p := tea.NewProgram(model, tea.WithAltScreen())
 	finalModel, err := p.Run()
 
 	// Clean up connections from the final model state
 	if m, ok := finalModel.(Model); ok {
-		if m.viewer != nil {
-			m.viewer.Stop()
-		}
-		if m.discovery != nil {
-			m.discovery.Stop()
-		}
-		if m.nc != nil && m.nc.IsConnected() {
-			m.nc.Close()
-		}
+		closeConnection(m.nc, m.viewer, m.discovery, m.jetstream, m.config.NatsDrainTimeout())
 	}
 
 	return err
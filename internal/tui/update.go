@@ -4,64 +4,229 @@
 package tui
 
 import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/eallender/nats-ls/internal/logger"
+	"github.com/eallender/nats-ls/internal/monitor"
 )
 
 // Update implements tea.Model
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		m.statusMessage = ""
+
+		// Answer a pending quit prompt, any key other than yes cancels it
+		if m.confirmingQuit {
+			m.confirmingQuit = false
+			if key := msg.String(); key == "y" || m.keys.Quit.matches(key) {
+				m.quitting = true
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+
+		// If the search prompt is active, handle its input
+		if m.searchActive {
+			return m.updateSearch(msg)
+		}
+
 		// If command bar is active, handle its input
 		if m.commandBarActive {
+			if msg.String() == "tab" {
+				return m.complete(), nil
+			}
+			m = m.clearCompletions()
+
 			switch msg.String() {
 			case "enter":
-				// TODO: Implement command processing (e.g., filter subjects by pattern)
-				// For now, just clear the command bar
+				input := m.commandInput
 				m.commandBarActive = false
 				m.commandInput = ""
+				m = m.addToHistory(input)
+				return m.runCommand(input)
 			case "esc":
 				m.commandBarActive = false
 				m.commandInput = ""
-			case "backspace":
-				if len(m.commandInput) > 0 {
-					m.commandInput = m.commandInput[:len(m.commandInput)-1]
-				}
+			case "up":
+				m = m.recallHistory(-1)
+			case "down":
+				m = m.recallHistory(1)
 			default:
-				// Add character to input
-				m.commandInput += msg.String()
+				m = m.editCommandInput(msg)
 			}
 			return m, nil
 		}
 
-		// Normal mode key handling
-		switch msg.String() {
-		case ":":
+		// Normal mode key handling. A count prefix typed before a movement key jumps
+		// straight to that row, like vim, and any other key drops it
+		count := m.pendingCount
+		m.pendingCount = 0
+
+		switch key := msg.String(); {
+		case m.keys.Command.matches(key):
 			m.commandBarActive = true
 			m.commandInput = ""
-		case "q", "ctrl+c":
+			m.historyIndex = len(m.commandHistory)
+			return m, nil
+		case m.keys.Filter.matches(key):
+			if m.watching == "" && m.consumerStream == "" {
+				m.searchActive = true
+				m.searchIndex = 0
+				m.commandInput = ""
+				return m, nil
+			}
+		case m.keys.Quit.matches(key):
+			// Confirm before dropping an active subscription, unless forced with ctrl+c
+			if key != "ctrl+c" && m.watching != "" && m.config != nil && m.config.ConfirmQuit {
+				m.confirmingQuit = true
+				m.statusMessage = "Quit? (y/n)"
+				return m, nil
+			}
 			m.quitting = true
 			return m, tea.Quit
-		case "up", "k":
+		case key == "H":
+			m.compactHeader = !m.compactHeader
+			return m, nil
+		}
+
+		// The logs view overlays every other view until closed
+		if m.showLogs {
+			return m.updateLogs(msg)
+		}
+
+		// Diagnostics overlay the subject tree until closed
+		if m.showDiagnostics {
+			return m.updateDiagnostics(msg)
+		}
+		if m.subjectDetail != "" {
+			return m.updateSubjectDetail(msg)
+		}
+		if m.showBookmarks {
+			return m.updateBookmarks(msg)
+		}
+		if m.showRecent {
+			return m.updateRecent(msg)
+		}
+
+		if m.keys.Logs.matches(msg.String()) {
+			m.showLogs = true
+			m.logScroll = 0
+			return m.loadLogs(), nil
+		}
+
+		// While watching a subject, keys navigate the message viewer
+		if m.watching != "" {
+			return m.updateViewer(msg)
+		}
+
+		// The consumer view only needs a way back to the subject tree
+		if m.consumerStream != "" {
+			if m.keys.GoBack.matches(msg.String()) {
+				m.consumerStream = ""
+				m.consumers = nil
+				m.consumersErr = nil
+			}
+			return m, nil
+		}
+
+		switch key := msg.String(); {
+		case isCountDigit(key, count):
+			m.pendingCount = min(count*10+int(key[0]-'0'), maxPendingCount)
+			m.statusMessage = fmt.Sprintf("Go to row %d", m.pendingCount)
+		case count > 0 && m.isCountMovement(key):
+			m.selectedIndex = max(min(count-1, len(m.getSubjectsAtCurrentLevel())-1), 0)
+		case m.keys.NavigateUp.matches(key):
 			if m.selectedIndex > 0 {
 				m.selectedIndex--
 			}
-		case "down", "j":
+		case m.keys.NavigateDown.matches(key):
 			nodes := m.getSubjectsAtCurrentLevel()
 			if m.selectedIndex < len(nodes)-1 {
 				m.selectedIndex++
 			}
-		case "enter":
+		case key == "pgup":
+			m.selectedIndex = max(m.selectedIndex-m.subjectPageSize(), 0)
+		case key == "pgdown":
+			nodes := m.getSubjectsAtCurrentLevel()
+			m.selectedIndex = max(min(m.selectedIndex+m.subjectPageSize(), len(nodes)-1), 0)
+		case key == "home" || key == "g":
+			m.selectedIndex = 0
+		case key == "end" || key == "G":
+			m.selectedIndex = max(len(m.getSubjectsAtCurrentLevel())-1, 0)
+		case key == "~":
+			// Jump back to the root of the tree in one step
+			m.navPath = nil
+			m.selectedIndex = 0
+		case m.keys.DrillDown.matches(key):
 			// Drill down into the selected subject
 			nodes := m.getSubjectsAtCurrentLevel()
 			if len(nodes) > 0 && m.selectedIndex < len(nodes) {
 				selectedNode := nodes[m.selectedIndex]
-				// Only drill down if it's not a leaf (i.e., has children)
-				if !selectedNode.IsLeaf {
+				// Only drill down if it's not a leaf (i.e., has children) or collapsed at the depth limit
+				if !selectedNode.IsLeaf && !selectedNode.Collapsed {
 					m.navPath = append(m.navPath, selectedNode.Name)
 					m.selectedIndex = 0
+				} else if !selectedNode.IsLeaf && m.separator() != "." {
+					// NATS wildcards only match whole dot separated tokens
+					m.statusMessage = prefixWatchUnsupported
+				} else {
+					// Leaves are complete subjects, so start watching their messages,
+					// collapsed prefixes watch everything below them
+					m = m.watch(selectedNode.FullName(m.navPath, m.separator()))
 				}
 			}
-		case "esc":
+		case key == "i":
+			// Toggle ignored subjects (e.g. _INBOX.), returning to the root since they may disappear
+			if m.discovery != nil {
+				m.discovery.SetShowIgnored(!m.discovery.ShowIgnored())
+				m.navPath = nil
+				m.selectedIndex = 0
+			}
+		case key == " ":
+			m = m.togglePause()
+		case key == "a":
+			m.activeOnly = !m.activeOnly
+			m = m.clampSelection()
+		case key == "t":
+			m.absoluteTimes = !m.absoluteTimes
+		case key == "y":
+			m = m.copySelectedSubject()
+		case key == "d":
+			m.showDiagnostics = true
+		case key == "s":
+			// Show everything known about the selected subject
+			nodes := m.getSubjectsAtCurrentLevel()
+			if m.selectedIndex >= len(nodes) || !nodes[m.selectedIndex].IsLeaf {
+				m.statusMessage = "Select a subject to show its stats"
+				break
+			}
+			node := nodes[m.selectedIndex]
+			m.subjectDetail = node.FullName(m.navPath, m.separator())
+			m.subjectDetailStream = node.Stream
+		case key == "b":
+			m = m.toggleBookmark()
+		case key == "B":
+			m.showBookmarks = true
+			m.bookmarkIndex = 0
+		case key == "r":
+			m.showRecent = true
+			m.recentIndex = 0
+		case key == "c":
+			// Show the consumers of the selected node's JetStream stream
+			nodes := m.getSubjectsAtCurrentLevel()
+			if m.selectedIndex >= len(nodes) || nodes[m.selectedIndex].Stream == "" {
+				m.statusMessage = "Selected subject is not part of a JetStream stream"
+				break
+			}
+			m.consumerStream = nodes[m.selectedIndex].Stream
+			m.consumers = nil
+			m.consumersErr = nil
+			return m, m.fetchConsumersCmd(m.consumerStream)
+		case m.keys.GoBack.matches(key):
 			// Go back up one level
 			if len(m.navPath) > 0 {
 				m.navPath = m.navPath[:len(m.navPath)-1]
@@ -71,24 +236,390 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		m = m.clampSelection()
 	case connectAttemptMsg:
+		if msg.address != m.config.NatsAddress {
+			// The attempt was against the server :connect switched away from, drop it and
+			// let the next tick try the new one
+			if msg.err == nil {
+				return m, tea.Batch(closeConnectionCmd(msg.nc, msg.viewer, msg.discovery, msg.jetstream, m.config.NatsDrainTimeout()), tickCmd)
+			}
+			return m, tickCmd
+		}
 		if msg.err != nil {
 			// Connection failed, retry after a delay
-			return m, tickCmd
+			m.connectErr = msg.err
+			m.connectTries++
+			if m.switchingTo != "" {
+				m.statusMessage = fmt.Sprintf("Could not connect to %s: %v", m.switchingTo, msg.err)
+				m.switchingTo = ""
+			}
+			var spin tea.Cmd
+			m, spin = m.startSpinner()
+			return m, tea.Batch(tickCmd, spin)
 		}
 		// Connection successful, update model
+		if m.switchingTo != "" {
+			m.statusMessage = "Connected to " + m.switchingTo
+			m.switchingTo = ""
+		}
+		m.connectErr = nil
+		m.connectTries = 0
 		m.nc = msg.nc
 		m.viewer = msg.viewer
 		m.discovery = msg.discovery
+		m.jetstream = msg.jetstream
+		m.watching = ""
+		if m.metrics != nil {
+			m.metrics.SetSource(msg.nc, msg.discovery)
+		}
+		m = m.watchStartSubject()
 		// Start the tick loop to refresh the UI
 		return m, tickCmd
+	case spinnerMsg:
+		// Keep spinning until connected
+		if m.IsConnected() {
+			m.spinning = false
+			m.spinnerFrame = 0
+			return m, nil
+		}
+		m.spinnerFrame++
+		return m, spinnerCmd
+	case statusMsg:
+		m.statusMessage = string(msg)
+	case consumersMsg:
+		// Ignore results for a stream that's no longer being viewed
+		if msg.stream == m.consumerStream {
+			m.consumers = msg.consumers
+			m.consumersErr = msg.err
+		}
 	case tickMsg:
-		// If not connected, try to reconnect
+		// If not connected, try to reconnect. The attempt schedules the next tick once it
+		// fails, so slow attempts don't pile up
 		if !m.IsConnected() {
-			return m, tea.Batch(m.tryConnect, tickCmd)
+			m.messageRate = 0
+			m.countedAt = time.Time{}
+			m.dropped = 0
+			m.dropping = false
+			var spin tea.Cmd
+			m, spin = m.startSpinner()
+			return m, tea.Batch(m.tryConnect, spin)
+		}
+		// Keep the logs view tailing the log file
+		if m.showLogs {
+			m = m.loadLogs()
+		}
+		if m.discovery != nil {
+			m = m.countMessages(time.Time(msg))
+			m = m.checkDropped(time.Time(msg))
+		}
+		// Sample round-trip latency to show connection health
+		cmds := []tea.Cmd{rttCmd(m.nc), tickCmd}
+		// Keep the consumer view up to date
+		if m.consumerStream != "" && m.jetstream != nil {
+			cmds = append(cmds, m.fetchConsumersCmd(m.consumerStream))
+		}
+		// Refresh the UI periodically to show new subjects
+		return m, tea.Batch(cmds...)
+	case rttMsg:
+		// Ignore samples from a connection that's since been replaced
+		if msg.nc == m.nc {
+			m.rtt, m.rttErr = msg.rtt, msg.err
 		}
-		// Otherwise just refresh the UI periodically to show new subjects
-		return m, tickCmd
 	}
 	return m, nil
 }
+
+// editCommandInput applies a key press to the command bar input
+func (m Model) editCommandInput(msg tea.KeyMsg) Model {
+	switch msg.Type {
+	case tea.KeyBackspace:
+		if len(m.commandInput) > 0 {
+			_, size := utf8.DecodeLastRuneInString(m.commandInput)
+			m.commandInput = m.commandInput[:len(m.commandInput)-size]
+		}
+	case tea.KeySpace:
+		m.commandInput += " "
+	case tea.KeyRunes:
+		m.commandInput += string(msg.Runes)
+	}
+	return m
+}
+
+// updateSearch handles key presses while the search prompt is active
+func (m Model) updateSearch(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		results := m.searchResults()
+		if m.searchIndex < len(results) {
+			m = m.jumpToSubject(results[m.searchIndex].Subject)
+		}
+		m.searchActive = false
+		m.commandInput = ""
+	case "esc":
+		m.searchActive = false
+		m.commandInput = ""
+	case "up":
+		if m.searchIndex > 0 {
+			m.searchIndex--
+		}
+	case "down":
+		if m.searchIndex < len(m.searchResults())-1 {
+			m.searchIndex++
+		}
+	default:
+		m = m.editCommandInput(msg)
+		m.searchIndex = 0
+	}
+	return m, nil
+}
+
+// updateLogs handles key presses while the logs view is shown
+func (m Model) updateLogs(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch key := msg.String(); {
+	case m.keys.NavigateUp.matches(key):
+		if m.logScroll < len(m.logLines)-1 {
+			m.logScroll++
+		}
+	case m.keys.NavigateDown.matches(key):
+		if m.logScroll > 0 {
+			m.logScroll--
+		}
+	case m.keys.GoBack.matches(key) || m.keys.Logs.matches(key):
+		m.showLogs = false
+		m.logLines = nil
+		m.logErr = nil
+	}
+	return m, nil
+}
+
+// updateRecent handles key presses while the recently changed subjects are shown
+func (m Model) updateRecent(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch key := msg.String(); {
+	case m.keys.NavigateUp.matches(key):
+		if m.recentIndex > 0 {
+			m.recentIndex--
+		}
+	case m.keys.NavigateDown.matches(key):
+		if m.recentIndex < len(m.recentSubjects())-1 {
+			m.recentIndex++
+		}
+	case m.keys.DrillDown.matches(key):
+		if recent := m.recentSubjects(); m.recentIndex < len(recent) {
+			m.showRecent = false
+			subject := recent[m.recentIndex].Name
+			m = m.jumpToSubject(subject).watch(subject)
+		}
+	case m.keys.GoBack.matches(key) || key == "r":
+		m.showRecent = false
+	}
+	return m, nil
+}
+
+// updateDiagnostics handles key presses while the diagnostics view is shown
+func (m Model) updateDiagnostics(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if key := msg.String(); m.keys.GoBack.matches(key) || key == "d" {
+		m.showDiagnostics = false
+	}
+	return m, nil
+}
+
+// updateSubjectDetail handles key presses while a subject's stats are shown
+func (m Model) updateSubjectDetail(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if key := msg.String(); m.keys.GoBack.matches(key) || key == "s" {
+		m.subjectDetail = ""
+		m.subjectDetailStream = ""
+	}
+	return m, nil
+}
+
+// updateBookmarks handles key presses while the bookmarks list is shown
+func (m Model) updateBookmarks(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch key := msg.String(); {
+	case m.keys.NavigateUp.matches(key):
+		if m.bookmarkIndex > 0 {
+			m.bookmarkIndex--
+		}
+	case m.keys.NavigateDown.matches(key):
+		if m.bookmarkIndex < len(m.bookmarks)-1 {
+			m.bookmarkIndex++
+		}
+	case m.keys.DrillDown.matches(key):
+		if m.bookmarkIndex < len(m.bookmarks) {
+			m.showBookmarks = false
+			m = m.jumpToBookmark(m.bookmarks[m.bookmarkIndex])
+		}
+	case key == "x":
+		if m.bookmarkIndex < len(m.bookmarks) {
+			m = m.removeBookmark(m.bookmarks[m.bookmarkIndex])
+		}
+	case m.keys.GoBack.matches(key) || key == "B":
+		m.showBookmarks = false
+	}
+	return m, nil
+}
+
+// updateViewer handles key presses while watching a subject's messages
+func (m Model) updateViewer(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if m.detailMessage != nil {
+		switch key := msg.String(); {
+		case key == "p":
+			m.prettyPrint = !m.prettyPrint
+		case key == "x":
+			m.hexView = !m.hexView
+		case key == "y":
+			m = m.copyToClipboard("payload", string(m.detailMessage.Data))
+		case m.keys.GoBack.matches(key):
+			m.detailMessage = nil
+		}
+		return m, nil
+	}
+
+	count := m.viewer.GetMessageCount()
+
+	switch key := msg.String(); {
+	case m.keys.NavigateUp.matches(key):
+		// Scrolling manually stops following new messages
+		if index := m.currentMessageIndex(count); index >= 0 {
+			m.selectedMessageIndex = max(index-1, 0)
+			m.autoScroll = false
+		}
+	case m.keys.NavigateDown.matches(key):
+		if index := m.currentMessageIndex(count); index >= 0 {
+			m.selectedMessageIndex = min(index+1, count-1)
+			m.autoScroll = false
+		}
+	case key == "f":
+		// Resume following, jumping to the newest message
+		m.autoScroll = true
+	case key == "t":
+		m.absoluteTimes = !m.absoluteTimes
+	case key == "d":
+		m.viewer.SetDetectDuplicates(!m.viewer.DetectingDuplicates())
+	case key == "v":
+		m.viewer.SetValidateJSON(!m.viewer.ValidatingJSON())
+	case key == "c":
+		m.viewer.Clear()
+		m.selectedMessageIndex = 0
+		m.autoScroll = true
+		m.statusMessage = fmt.Sprintf("Cleared %d messages", count)
+	case m.keys.DrillDown.matches(key):
+		messages := m.viewer.GetMessages()
+		if index := m.currentMessageIndex(len(messages)); index >= 0 {
+			m.detailMessage = &messages[index]
+			// Start binary payloads in the hex view since they rarely print cleanly
+			m.hexView = monitor.GuessContentType(m.detailMessage.Data) == monitor.ContentTypeBinary
+		}
+	case m.keys.GoBack.matches(key):
+		// Stop watching and return to the subject tree
+		m = m.watch("")
+	}
+
+	return m, nil
+}
+
+// maxPendingCount caps the count prefix so typing many digits can't overflow
+const maxPendingCount = 1_000_000
+
+// isCountDigit reports whether key extends the count prefix. A leading zero isn't a count
+func isCountDigit(key string, count int) bool {
+	return len(key) == 1 && key[0] >= '0' && key[0] <= '9' && (key != "0" || count > 0)
+}
+
+// isCountMovement reports whether key applies a pending count prefix
+func (m Model) isCountMovement(key string) bool {
+	return m.keys.NavigateUp.matches(key) || m.keys.NavigateDown.matches(key) ||
+		m.keys.DrillDown.matches(key) || key == "enter" || key == "g" || key == "G"
+}
+
+// countMessages refreshes the message count and derives the overall rate from how much
+// it grew since the last refresh. A count that went backwards belongs to a new discovery
+// after reconnecting, so the rate restarts from the next refresh
+func (m Model) countMessages(now time.Time) Model {
+	count := m.discovery.TotalMessages()
+	m.messageRate = 0
+	if elapsed := now.Sub(m.countedAt).Seconds(); !m.countedAt.IsZero() && elapsed > 0 && count >= m.messageCount {
+		m.messageRate = float64(count-m.messageCount) / elapsed
+	}
+	m.messageCount = count
+	m.countedAt = now
+	return m
+}
+
+// dropWarningHold is how long the dropping messages warning stays up after the last drop,
+// so it doesn't flicker while discovery keeps falling behind
+const dropWarningHold = 5 * time.Second
+
+// checkDropped raises the dropping messages warning while discovery is dropping messages
+func (m Model) checkDropped(now time.Time) Model {
+	dropped := m.discovery.Dropped()
+	if dropped > m.dropped {
+		logger.Log.Warn("Discovery is dropping messages", "dropped", dropped-m.dropped)
+		m.lastDropAt = now
+	}
+	m.dropped = dropped
+	m.dropping = !m.lastDropAt.IsZero() && now.Sub(m.lastDropAt) < dropWarningHold
+	return m
+}
+
+// clampSelection keeps the selection and scroll state within the content currently
+// available, so a resize never leaves them pointing past what can be shown
+func (m Model) clampSelection() Model {
+	m.selectedIndex = max(min(m.selectedIndex, len(m.getSubjectsAtCurrentLevel())-1), 0)
+	m.logScroll = max(min(m.logScroll, len(m.logLines)-1), 0)
+	if m.viewer != nil {
+		m.selectedMessageIndex = max(min(m.selectedMessageIndex, m.viewer.GetMessageCount()-1), 0)
+	}
+	return m
+}
+
+// currentMessageIndex returns the selected message index for a list of count messages
+func (m Model) currentMessageIndex(count int) int {
+	if m.autoScroll || m.selectedMessageIndex >= count {
+		return count - 1
+	}
+	return m.selectedMessageIndex
+}
+
+// watchStartSubject watches the --watch subject the first time a connection is up,
+// leaving the subject tree to the user after that
+func (m Model) watchStartSubject() Model {
+	if m.startSubject == "" || m.viewer == nil {
+		return m
+	}
+
+	subject := m.startSubject
+	m.startSubject = ""
+	return m.watch(subject)
+}
+
+// watch points the viewer at a subject, or stops watching if subject is empty
+func (m Model) watch(subject string) Model {
+	if m.viewer == nil {
+		return m
+	}
+
+	if err := m.viewer.Watch(subject); err != nil {
+		logger.Log.Warn("Failed to watch subject", "subject", subject, "error", err)
+		m.statusMessage = fmt.Sprintf("Failed to watch %s: %v", subject, err)
+		m.watching = ""
+		return m
+	}
+
+	m.watching = subject
+	m.autoScroll = true
+	m.detailMessage = nil
+	m.hexView = false
+	m.messageFilter = ""
+
+	// Pretty print by default unless the subject is known not to carry JSON, and
+	// validate payloads of subjects known to carry it
+	if m.discovery != nil {
+		if info, ok := m.discovery.GetSubject(subject); ok {
+			contentType := info.ContentType()
+			m.prettyPrint = contentType == monitor.ContentTypeJSON || contentType == monitor.ContentTypeUnknown
+			m.viewer.SetValidateJSON(contentType == monitor.ContentTypeJSON)
+		}
+	}
+	return m
+}
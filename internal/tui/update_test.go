@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/eallender/nats-ls/internal/config"
+	"github.com/eallender/nats-ls/internal/monitor"
+	"github.com/nats-io/nats.go"
+)
+
+func TestRTTMsgIgnoresReplacedConnection(t *testing.T) {
+	current, replaced := &nats.Conn{}, &nats.Conn{}
+	m := Model{nc: current}
+
+	updated, _ := m.Update(rttMsg{nc: current, rtt: 3 * time.Millisecond})
+	m = updated.(Model)
+	if m.rtt != 3*time.Millisecond || m.rttErr != nil {
+		t.Fatalf("rtt = %v, %v; want 3ms, nil", m.rtt, m.rttErr)
+	}
+
+	updated, _ = m.Update(rttMsg{nc: replaced, err: errors.New("timeout")})
+	m = updated.(Model)
+	if m.rtt != 3*time.Millisecond || m.rttErr != nil {
+		t.Errorf("sample from a replaced connection was applied: rtt = %v, %v", m.rtt, m.rttErr)
+	}
+}
+
+func TestResizeClampsSelection(t *testing.T) {
+	m := pausedModel(&config.Config{},
+		monitor.SubjectSnapshot{Name: "orders.new"},
+		monitor.SubjectSnapshot{Name: "orders.paid"},
+		monitor.SubjectSnapshot{Name: "orders.sent"},
+	)
+	m.navPath = []string{"orders"}
+	m.viewer = monitor.NewViewer(nil, 10)
+	m.logLines = []string{"first", "second"}
+
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 200, Height: 60})
+	m = updated.(Model)
+	m.selectedIndex = 2
+	m.logScroll = 1
+
+	// The selected subject goes away while the window shrinks
+	m.pausedSubjects = m.pausedSubjects[:1]
+	m.logLines = m.logLines[:1]
+	m.selectedMessageIndex = 5
+	updated, _ = m.Update(tea.WindowSizeMsg{Width: 20, Height: 5})
+	m = updated.(Model)
+
+	if m.selectedIndex != 0 {
+		t.Errorf("selectedIndex = %d, want 0", m.selectedIndex)
+	}
+	if m.logScroll != 0 {
+		t.Errorf("logScroll = %d, want 0", m.logScroll)
+	}
+	if m.selectedMessageIndex != 0 {
+		t.Errorf("selectedMessageIndex = %d, want 0", m.selectedMessageIndex)
+	}
+	// Rendering at the small size must not panic
+	_ = m.View()
+}
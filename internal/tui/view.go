@@ -5,10 +5,15 @@ package tui
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/eallender/nats-ls/internal/logger"
+	"github.com/eallender/nats-ls/internal/monitor"
 )
 
 // View implements tea.Model
@@ -24,26 +29,92 @@ func (m Model) View() string {
 
 	// Render header and command bar first to measure their heights
 	header := m.renderHeader()
+	if m.compactHeader {
+		header = m.renderCompactHeader()
+	}
 	commandBar := m.renderCommandBar()
 
-	// Calculate available height for content based on actual component heights
-	headerHeight := lipgloss.Height(header)
-	commandBarHeight := lipgloss.Height(commandBar)
-	contentHeight := m.height - headerHeight - commandBarHeight
+	// Build content with the height left by the header and command bar
+	content := m.renderContentWithHeight(m.contentHeight(header, commandBar))
+
+	// Combine all sections
+	if commandBar != "" {
+		return lipgloss.JoinVertical(lipgloss.Left, header, commandBar, content)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, header, content)
+}
+
+// contentHeight returns the height available for content based on the rendered
+// header and command bar heights
+func (m Model) contentHeight(header, commandBar string) int {
+	contentHeight := m.height - lipgloss.Height(header) - lipgloss.Height(commandBar)
 
 	// Ensure we don't create content that's too tall
 	if contentHeight < 1 {
 		contentHeight = 1
 	}
+	return contentHeight
+}
+
+// subjectTableRows returns how many subject rows fit in content of the given height,
+// below the table header and the navigation path title
+func (m Model) subjectTableRows(contentHeight int) int {
+	// Enforce minimum content height (must account for frame overhead)
+	minRequiredHeight := MinContentHeight + GetFrameHeight(NavStyle)
+	if contentHeight < minRequiredHeight {
+		contentHeight = minRequiredHeight
+	}
 
-	// Build content with calculated height
-	content := m.renderContentWithHeight(contentHeight)
+	rows := MaxContentHeight(contentHeight, NavStyle) - 1
+	if len(m.navPath) > 0 {
+		rows -= 2
+	}
+	return max(rows, 1)
+}
 
-	// Combine all sections
-	if m.commandBarActive {
-		return lipgloss.JoinVertical(lipgloss.Left, header, commandBar, content)
+// subjectPageSize returns how many subject rows are currently on screen
+func (m Model) subjectPageSize() int {
+	header := m.renderHeader()
+	if m.compactHeader {
+		header = m.renderCompactHeader()
 	}
-	return lipgloss.JoinVertical(lipgloss.Left, header, content)
+	return m.subjectTableRows(m.contentHeight(header, m.renderCommandBar()))
+}
+
+// renderCompactHeader creates a single-line header with the connection status, server,
+// message count and active filter
+func (m Model) renderCompactHeader() string {
+	var status string
+	if m.IsConnected() {
+		status = HeaderConnectedStyle.Render("● " + m.formatRTT())
+	} else {
+		status = HeaderDisconnectedStyle.Render("● Disconnected " + m.reconnectStatus())
+	}
+
+	parts := []string{
+		HeaderAppNameStyle.UnsetMarginRight().Render("NLS") + status,
+		HeaderServerStyle.Render(m.currentServer()),
+		HeaderStatsStyle.Render(fmt.Sprintf("Messages: %d  Rate: %s", m.messageCount, formatRate(m.messageRate))),
+	}
+	if m.filter != "" {
+		parts = append(parts, HeaderFilterStyle.Render("Filter: "+m.filter))
+	}
+	if m.paused {
+		parts = append(parts, HeaderFilterStyle.Render("PAUSED"))
+	}
+	if m.activeOnly {
+		parts = append(parts, HeaderFilterStyle.Render(m.activeLabel()))
+	}
+	if m.dropping {
+		parts = append(parts, HeaderWarningStyle.Render(droppingWarningShort))
+	}
+
+	// Width sets content area, so account for horizontal padding (1 left + 1 right = 2)
+	line := strings.Join(parts, HeaderDividerStyle.Render("│"))
+	return HeaderContainerStyle.
+		Width(m.width-2).
+		Padding(0, 1).
+		Render(truncate(line, m.width-2))
 }
 
 // renderHeader creates the header bar with app info and status
@@ -57,7 +128,25 @@ func (m Model) renderHeader() string {
 		} else {
 			status = HeaderDisconnectedStyle.Render(status)
 		}
-		simpleHeader := fmt.Sprintf("NLS %s | q:quit", status)
+		if m.IsConnected() {
+			status += m.formatRTT()
+		} else {
+			status += HeaderDisconnectedStyle.UnsetPadding().Render(m.spinner())
+		}
+		parts := []string{"NLS " + status}
+		if m.filter != "" {
+			parts = append(parts, HeaderFilterStyle.Render(m.filter))
+		}
+		if m.paused {
+			parts = append(parts, HeaderFilterStyle.Render("PAUSED"))
+		}
+		if m.activeOnly {
+			parts = append(parts, HeaderFilterStyle.Render(m.activeLabel()))
+		}
+		if m.dropping {
+			parts = append(parts, HeaderWarningStyle.Render(droppingWarningShort))
+		}
+		simpleHeader := strings.Join(append(parts, "q:quit"), " | ")
 		return HeaderContainerStyle.
 			Width(m.width).
 			Padding(0, 1).
@@ -72,29 +161,43 @@ func (m Model) renderHeader() string {
 	var statusStyle lipgloss.Style
 	if m.IsConnected() {
 		statusStyle = HeaderConnectedStyle
-		statusText = "● Connected"
+		statusText = fmt.Sprintf("● Connected (%s)", m.formatRTT())
 	} else {
 		statusStyle = HeaderDisconnectedStyle
 		statusText = "● Disconnected"
+		if m.connectErr != nil {
+			statusText += " (" + truncate(m.connectErr.Error(), maxHeaderErrorLen) + ")"
+		}
 	}
 
 	status := statusStyle.Render(statusText)
-	server := HeaderServerStyle.Render(fmt.Sprintf("Server: %s", m.serverURL))
-	msgCount := HeaderStatsStyle.Render(fmt.Sprintf("Messages: %d", m.messageCount))
-	statusInfo := HeaderStatusInfoStyle.Render(lipgloss.JoinVertical(
-		lipgloss.Left,
-		"",
-		status,
-		server,
-		msgCount,
-	))
+	server := HeaderServerStyle.Render(fmt.Sprintf("Server: %s", m.currentServer()))
+	msgCount := HeaderStatsStyle.Render(fmt.Sprintf("Messages: %d  Rate: %s", m.messageCount, formatRate(m.messageRate)))
+	statusLines := []string{"", status}
+	if !m.IsConnected() {
+		statusLines = append(statusLines, HeaderDisconnectedStyle.Render(m.reconnectStatus()))
+	}
+	statusLines = append(statusLines, server, msgCount)
+	if m.filter != "" {
+		statusLines = append(statusLines, HeaderFilterStyle.Render(fmt.Sprintf("Filter: %s", m.filter)))
+	}
+	if m.paused {
+		statusLines = append(statusLines, HeaderFilterStyle.Render("PAUSED"))
+	}
+	if m.activeOnly {
+		statusLines = append(statusLines, HeaderFilterStyle.Render(m.activeLabel()))
+	}
+	if m.dropping {
+		statusLines = append(statusLines, HeaderWarningStyle.Render(droppingWarning))
+	}
+	statusInfo := HeaderStatusInfoStyle.Render(lipgloss.JoinVertical(lipgloss.Left, statusLines...))
 
 	controls1 := HeaderControlStyle.Render(lipgloss.JoinVertical(
 		lipgloss.Left,
 		"",
-		"<enter>",
-		"<esc>",
-		"<↑↓>",
+		keyHint(m.keys.DrillDown),
+		keyHint(m.keys.GoBack),
+		keyHint(m.keys.NavigateUp, m.keys.NavigateDown),
 	))
 
 	controlsInfo1 := HeaderControlStyleInfo.Render(lipgloss.JoinVertical(
@@ -110,9 +213,9 @@ func (m Model) renderHeader() string {
 		Render(lipgloss.JoinVertical(
 			lipgloss.Left,
 			"",
-			"<l>",
-			"<:>",
-			"<q>",
+			keyHint(m.keys.Logs),
+			keyHint(m.keys.Command),
+			keyHint(m.keys.Quit),
 		))
 
 	controlsInfo2 := HeaderControlStyleInfo.Render(lipgloss.JoinVertical(
@@ -137,11 +240,66 @@ func (m Model) renderHeader() string {
 	// Apply container style with padding and width
 	// Width sets content area, so account for horizontal padding (1 left + 1 right = 2)
 	return HeaderContainerStyle.
-		Width(m.width - 2).
+		Width(m.width-2).
 		Padding(0, 1).
 		Render(headerContent)
 }
 
+// Shown in the header while discovery is dropping messages
+const (
+	droppingWarning      = "⚠ dropping messages — discovery may be incomplete"
+	droppingWarningShort = "⚠ dropping messages"
+)
+
+// spinner returns the current frame of the reconnecting spinner
+func (m Model) spinner() string {
+	return string(spinnerFrames[m.spinnerFrame%len(spinnerFrames)])
+}
+
+// reconnectStatus describes the ongoing reconnect attempts, e.g. "⠋ reconnecting (3 attempts)"
+func (m Model) reconnectStatus() string {
+	switch m.connectTries {
+	case 0:
+		return m.spinner() + " connecting"
+	case 1:
+		return m.spinner() + " reconnecting (1 attempt)"
+	}
+	return fmt.Sprintf("%s reconnecting (%d attempts)", m.spinner(), m.connectTries)
+}
+
+// formatRTT formats the last sampled round-trip latency, or "—" if unavailable
+func (m Model) formatRTT() string {
+	if m.rttErr != nil || m.rtt == 0 {
+		return "—"
+	}
+	if m.rtt < time.Millisecond {
+		return "<1ms"
+	}
+	return fmt.Sprintf("%dms", m.rtt.Milliseconds())
+}
+
+// currentServer returns the server we're connected to, or the configured address
+func (m Model) currentServer() string {
+	if m.IsConnected() {
+		if url := m.nc.ConnectedUrlRedacted(); url != "" {
+			return url
+		}
+	}
+	return m.serverURL
+}
+
+// subjectCountLabel formats a subject count for the path title, e.g. "(14 subjects)"
+func subjectCountLabel(count int, narrow bool) string {
+	switch {
+	case narrow:
+		return fmt.Sprintf("(%d)", count)
+	case count == 1:
+		return "(1 subject)"
+	default:
+		return fmt.Sprintf("(%d subjects)", count)
+	}
+}
+
 // renderContentWithHeight creates the main content area with a single full-width panel
 func (m Model) renderContentWithHeight(contentHeight int) string {
 	// Enforce minimum content height (must account for frame overhead)
@@ -166,66 +324,57 @@ func (m Model) renderContentWithHeight(contentHeight int) string {
 	// Build main content with hierarchical subjects as a table
 	var mainText string
 
-	if m.discovery != nil {
-		// Add path as a title line if drilled down
-		if len(m.navPath) > 0 {
-			pathDisplay := strings.Join(m.navPath, ".") + " >"
-			// Create a styled title that looks like it's part of the border
-			titleLen := len(pathDisplay)
-
-			// Ensure title fits within available width
-			if titleLen+4 > contentWidth {
-				// Truncate path if too long (leave room for spaces and dashes)
-				maxPathLen := contentWidth - 4 // Reserve space for " " + " " and at least 2 dashes
-				if maxPathLen > 0 {
-					pathDisplay = pathDisplay[:maxPathLen] + ">"
-					titleLen = len(pathDisplay)
-				} else {
-					// Terminal too narrow for title
-					pathDisplay = ">"
-					titleLen = 1
-				}
-			}
-
-			leftDashes := (contentWidth - titleLen - 2) / 2
-			if leftDashes < 0 {
-				leftDashes = 0
-			}
-			rightDashes := contentWidth - titleLen - 2 - leftDashes
-			if rightDashes < 0 {
-				rightDashes = 0
-			}
+	if m.showLogs {
+		mainText = m.renderLogs(contentWidth, contentHeightAdjusted)
+	} else if m.showDiagnostics {
+		mainText = m.renderDiagnostics(contentWidth)
+	} else if m.subjectDetail != "" {
+		mainText = m.renderSubjectDetail(contentWidth)
+	} else if m.showBookmarks {
+		mainText = m.renderBookmarks(contentWidth, contentHeightAdjusted)
+	} else if m.showRecent {
+		mainText = m.renderRecent(contentWidth, contentHeightAdjusted)
+	} else if m.searchActive {
+		mainText = m.renderSearchResults(contentWidth, contentHeightAdjusted)
+	} else if m.consumerStream != "" {
+		mainText = m.renderConsumers(contentWidth)
+	} else if m.watching != "" && m.detailMessage != nil {
+		mainText = m.renderMessageDetail(contentWidth, contentHeightAdjusted)
+	} else if m.watching != "" && m.viewer != nil {
+		mainText = m.renderMessageList(contentWidth, contentHeightAdjusted)
+	} else if m.discovery != nil {
+		nodes := m.getSubjectsAtCurrentLevel()
 
-			// Build title line with exact width (before styling)
-			rawTitle := strings.Repeat("─", leftDashes) + " " + pathDisplay + " " + strings.Repeat("─", rightDashes)
-			// Note: "─" is 3 bytes but 1 display column, so ensure display width not byte length
-			// Since we calculated leftDashes and rightDashes to fit contentWidth, this should be correct
-			// But add safety check for any edge cases with Unicode
-			titleLine := lipgloss.NewStyle().Foreground(ColorMuted).Render(rawTitle)
-			mainText = titleLine + "\n\n"
+		// Add path as a title line if drilled down, with how many subjects it holds
+		if len(m.navPath) > 0 {
+			title := strings.Join(m.navPath, m.separator()) + " > " + subjectCountLabel(len(nodes), NewLayout(m.width, m.height).IsNarrow())
+			mainText = renderTitleLine(title, contentWidth) + "\n\n"
 		}
 
-		nodes := m.getSubjectsAtCurrentLevel()
 		if len(nodes) > 0 {
 			// Calculate column widths dynamically based on available space
-			var msgColWidth, lastSeenColWidth, subjectColWidth int
-			spacingChars := 2 // spaces between columns
+			var msgColWidth, rateColWidth, sizeColWidth, lastSeenColWidth, subjectColWidth int
+			spacingChars := 4 // spaces between columns
 
 			// Scale columns based on available width
 			if contentWidth < 30 {
 				// Very narrow terminal - use minimal widths
 				msgColWidth = 6
+				rateColWidth = 6
+				sizeColWidth = 6
 				lastSeenColWidth = 8
-				subjectColWidth = contentWidth - msgColWidth - lastSeenColWidth - spacingChars
+				subjectColWidth = contentWidth - msgColWidth - rateColWidth - sizeColWidth - lastSeenColWidth - spacingChars
 				if subjectColWidth < 5 {
 					subjectColWidth = 5
 					// Recalculate total to ensure it fits
-					total := subjectColWidth + msgColWidth + lastSeenColWidth + spacingChars
+					total := subjectColWidth + msgColWidth + rateColWidth + sizeColWidth + lastSeenColWidth + spacingChars
 					if total > contentWidth {
 						// Scale down everything proportionally
 						msgColWidth = 4
+						rateColWidth = 4
+						sizeColWidth = 4
 						lastSeenColWidth = 6
-						subjectColWidth = contentWidth - msgColWidth - lastSeenColWidth - spacingChars
+						subjectColWidth = contentWidth - msgColWidth - rateColWidth - sizeColWidth - lastSeenColWidth - spacingChars
 						if subjectColWidth < 3 {
 							subjectColWidth = 3
 						}
@@ -234,33 +383,52 @@ func (m Model) renderContentWithHeight(contentHeight int) string {
 			} else {
 				// Normal width - use standard column sizes
 				msgColWidth = 10
-				lastSeenColWidth = 12
-				subjectColWidth = contentWidth - msgColWidth - lastSeenColWidth - spacingChars
+				rateColWidth = 8
+				sizeColWidth = 8
+				// Widen to fit absolute timestamps in longer layouts
+				lastSeenColWidth = max(12, ansi.StringWidth(m.formatTime(time.Now())))
+				subjectColWidth = contentWidth - msgColWidth - rateColWidth - sizeColWidth - lastSeenColWidth - spacingChars
 				// Ensure subject column has reasonable minimum
 				if subjectColWidth < 10 {
 					subjectColWidth = 10
 				}
 			}
 
+			// Show each subject's latest payload when previews are kept and there's room for them
+			previewColWidth := 0
+			if m.config != nil && m.config.NatsDiscoveryPreviewBytes > 0 && contentWidth >= minPreviewTableWidth {
+				previewColWidth = min(contentWidth/4, maxPreviewWidth)
+				subjectColWidth -= previewColWidth + 1
+			}
+
 			// Final safety check: ensure total width doesn't exceed contentWidth
-			totalWidth := subjectColWidth + msgColWidth + lastSeenColWidth + spacingChars
+			totalWidth := subjectColWidth + msgColWidth + rateColWidth + sizeColWidth + lastSeenColWidth + spacingChars
 			if totalWidth > contentWidth {
 				// Force subjectColWidth to fit within bounds
-				subjectColWidth = contentWidth - msgColWidth - lastSeenColWidth - spacingChars
+				subjectColWidth = contentWidth - msgColWidth - rateColWidth - sizeColWidth - lastSeenColWidth - spacingChars
 				if subjectColWidth < 1 {
 					subjectColWidth = 1
 				}
 			}
 
 			// Table header with dynamic column widths
-			headerText := fmt.Sprintf("%-*s %*s %*s", subjectColWidth, "SUBJECT", msgColWidth, "MESSAGES", lastSeenColWidth, "LAST SEEN")
+			headerText := fmt.Sprintf("%-*s %*s %*s %*s %*s", subjectColWidth, "SUBJECT", msgColWidth, "MESSAGES", rateColWidth, "RATE", sizeColWidth, "SIZE", lastSeenColWidth, "LAST SEEN")
+			if previewColWidth > 0 {
+				headerText += " PREVIEW"
+			}
 			// Ensure exact width to prevent wrapping
 			headerText = ensureWidth(headerText, contentWidth)
 			header := NavTableHeaderStyle.Render(headerText)
 			mainText += header + "\n"
 
+			// Show the window of rows that fits, keeping the selection visible
+			rows := m.subjectTableRows(contentHeight)
+			start := max(m.selectedIndex-rows+1, 0)
+			end := min(start+rows, len(nodes))
+
 			// Table rows
-			for i, node := range nodes {
+			for i := start; i < end; i++ {
+				node := nodes[i]
 				rowStyle := NavTableRowStyle
 				if i == m.selectedIndex {
 					rowStyle = NavTableSelectedRowStyle
@@ -269,27 +437,62 @@ func (m Model) renderContentWithHeight(contentHeight int) string {
 				// Display name with indicator for directories vs leaves
 				displayName := node.Name
 				if !node.IsLeaf {
-					displayName += ".>"
+					displayName += m.separator() + ">"
 				}
 
-				// Truncate if too long for the dynamic column width
-				maxDisplayLen := subjectColWidth
-				if len(displayName) > maxDisplayLen {
-					displayName = displayName[:maxDisplayLen-3] + "..."
+				// Truncate if too long for the dynamic column width, leaving room for badges
+				badge := ""
+				if m.isBookmarked(node.FullName(m.navPath, m.separator())) {
+					badge += " " + bookmarkMarker
+				}
+				if node.HasReplyTo {
+					badge += " R/R"
+				}
+				if node.Stream != "" {
+					badge += " JS"
+				}
+				if label := contentTypeBadge(node.ContentType); label != "" {
+					badge += " " + label
+				}
+				maxDisplayLen := subjectColWidth - ansi.StringWidth(badge)
+				if maxDisplayLen < 4 {
+					badge = ""
+					maxDisplayLen = subjectColWidth
 				}
 
+				// Highlight the part of the name the filter matched, except what truncation cut
+				var matched []int
+				if m.filter != "" {
+					matched = filterMatchPositions(m.filter, m.separator(), len(m.navPath), node.Name)
+				}
+				if truncated := truncate(displayName, maxDisplayLen); truncated != displayName {
+					kept := len(strings.TrimSuffix(truncated, "..."))
+					matched = slices.DeleteFunc(matched, func(pos int) bool { return pos >= kept })
+					displayName = truncated
+				}
+				displayName += badge
+
 				// Format last seen as relative time
-				lastSeenStr := formatRelativeTime(node.LastSeen)
+				lastSeenStr := m.formatTime(node.LastSeen)
 
-				rowText := fmt.Sprintf("%-*s %*d %*s", subjectColWidth, displayName, msgColWidth, node.MessageCount, lastSeenColWidth, lastSeenStr)
+				// Pad the subject by display width since fmt pads by runes, which misaligns wide characters
+				rowText := fmt.Sprintf("%s %*d %*s %*s %*s", ensureWidth(displayName, subjectColWidth), msgColWidth, node.MessageCount, rateColWidth, formatRate(node.Rate), sizeColWidth, formatBytes(node.TotalBytes), lastSeenColWidth, lastSeenStr)
+				if previewColWidth > 0 {
+					rowText += " " + truncate(previewText(node.Preview), previewColWidth)
+				}
 				// Ensure exact width to prevent wrapping
 				rowText = ensureWidth(rowText, contentWidth)
-				row := rowStyle.Render(rowText)
-				mainText += row + "\n"
+				matchStyle := SearchMatchStyle
+				if i == m.selectedIndex {
+					matchStyle = SearchMatchSelectedStyle
+				}
+				mainText += highlightMatches(rowText, matched, rowStyle, matchStyle) + "\n"
 			}
 		} else {
 			mainText += ensureWidth("No subjects discovered yet...", contentWidth)
 		}
+	} else if m.IsConnected() {
+		mainText = ensureWidth("Discovery is disabled. Type :sub <subject> to watch a subject or wildcard", contentWidth)
 	} else {
 		mainText = ensureWidth("Not connected...", contentWidth)
 	}
@@ -304,18 +507,741 @@ func (m Model) renderContentWithHeight(contentHeight int) string {
 	return content
 }
 
-// renderCommandBar creates the command input bar
+// renderTitleLine renders a title centered in a line of dashes so it looks like part of the border
+func renderTitleLine(title string, contentWidth int) string {
+	titleLen := ansi.StringWidth(title)
+
+	// Ensure title fits within available width
+	if titleLen+4 > contentWidth {
+		// Truncate title if too long (leave room for spaces and dashes)
+		maxTitleLen := contentWidth - 4 // Reserve space for " " + " " and at least 2 dashes
+		if maxTitleLen > 0 {
+			title = ansi.Truncate(title, maxTitleLen, "") + ">"
+			titleLen = ansi.StringWidth(title)
+		} else {
+			// Terminal too narrow for title
+			title = ">"
+			titleLen = 1
+		}
+	}
+
+	leftDashes := (contentWidth - titleLen - 2) / 2
+	if leftDashes < 0 {
+		leftDashes = 0
+	}
+	rightDashes := contentWidth - titleLen - 2 - leftDashes
+	if rightDashes < 0 {
+		rightDashes = 0
+	}
+
+	// Build title line with exact width (before styling)
+	// Note: "─" is 3 bytes but 1 display column, so the dash counts are display widths
+	rawTitle := strings.Repeat("─", leftDashes) + " " + title + " " + strings.Repeat("─", rightDashes)
+	return lipgloss.NewStyle().Foreground(ColorMuted).Render(rawTitle)
+}
+
+// jsonMarker marks a payload as valid or invalid JSON, blank if it wasn't checked
+func jsonMarker(valid *bool) string {
+	switch {
+	case valid == nil:
+		return " "
+	case *valid:
+		return "✓"
+	default:
+		return "✗"
+	}
+}
+
+// messageSizeWidth fits the widest payload size formatBytes renders, e.g. "1023.9KB"
+const messageSizeWidth = 8
+
+// missingExtract is shown in the extract column when a message has no value at the path
+const missingExtract = "—"
+
+// evictingMarker follows the message count once the store is full and evicting the oldest
+const evictingMarker = "⟳"
+
+// renderMessageList renders the most recent messages for the watched subject
+func (m Model) renderMessageList(contentWidth, contentHeight int) string {
+	messages := m.viewer.GetMessages()
+
+	// Show how full the store is, marking it once new messages evict the oldest
+	capacity := m.viewer.Capacity()
+	count := fmt.Sprintf("%d/%d messages", len(messages), capacity)
+	if len(messages) >= capacity {
+		count += " " + evictingMarker
+	}
+	title := fmt.Sprintf("%s (%s)", m.watching, count)
+	if m.messageFilter != "" {
+		title = fmt.Sprintf("%s (%s matching /%s/)", m.watching, count, m.messageFilter)
+	}
+	if headers := m.viewer.HeaderFilter(); headers != nil {
+		title += " with header " + headers.String()
+	}
+	extract := m.viewer.Extract()
+	if extract != nil {
+		title += " extracting " + extract.String()
+	}
+	if m.viewer.DetectingDuplicates() {
+		title += " [duplicates]"
+	}
+	validating := m.viewer.ValidatingJSON()
+	if validating {
+		title += " [json]"
+	}
+	if m.autoScroll {
+		title += " [following]"
+	} else {
+		title += " [scrolling, f to follow]"
+	}
+	mainText := renderTitleLine(title, contentWidth) + "\n\n"
+
+	if len(messages) == 0 {
+		return mainText + ensureWidth("Waiting for messages...", contentWidth)
+	}
+
+	// Show the window of messages that fits below the title, keeping the selection visible
+	maxRows := contentHeight - 2
+	if maxRows < 1 {
+		maxRows = 1
+	}
+	selected := m.currentMessageIndex(len(messages))
+	start := len(messages) - maxRows
+	if start < 0 {
+		start = 0
+	}
+	if selected < start {
+		start = selected
+	}
+	end := start + maxRows
+	if end > len(messages) {
+		end = len(messages)
+	}
+
+	// Size the extract column to the widest visible value
+	extractWidth := 0
+	if extract != nil {
+		extractWidth = ansi.StringWidth(missingExtract)
+		for _, msg := range messages[start:end] {
+			extractWidth = max(extractWidth, ansi.StringWidth(msg.Extracted))
+		}
+		extractWidth = min(extractWidth, maxExtractWidth)
+	}
+
+	for i := start; i < end; i++ {
+		msg := messages[i]
+		rowStyle := NavTableRowStyle
+		if i == selected {
+			rowStyle = NavTableSelectedRowStyle
+		} else if msg.Duplicate {
+			rowStyle = DuplicateRowStyle
+		}
+
+		// Keep each message on a single line, without control characters that garble the row
+		payload := previewText(m.previewPayload(msg))
+		if extract != nil {
+			value := msg.Extracted
+			if value == "" {
+				value = missingExtract
+			}
+			payload = ensureWidth(strings.ReplaceAll(value, "\n", " "), extractWidth) + "  " + payload
+		}
+		if validating {
+			payload = jsonMarker(msg.JSONValid) + " " + payload
+		}
+		size := formatBytes(int64(len(msg.Data)))
+		line := fmt.Sprintf("[%s] %*s %s %s: %s", m.formatTime(msg.Timestamp), messageSizeWidth, size, payloadGlyph(msg.Data), msg.Subject, payload)
+		mainText += rowStyle.Render(ensureWidth(line, contentWidth)) + "\n"
+	}
+
+	return mainText
+}
+
+// renderActivity renders a sparkline of the subject's recent per-second message counts
+func (m Model) renderActivity(subject string, width int) string {
+	if m.discovery == nil || m.config == nil || m.config.NatsDiscoveryHistorySeconds <= 0 {
+		return ""
+	}
+	info, ok := m.discovery.GetSubject(subject)
+	if !ok {
+		return ""
+	}
+	return sparkline(info.History(m.config.NatsDiscoveryHistorySeconds), width)
+}
+
+// renderSizeHistogram renders a bar per payload size bucket for the subject's stored messages
+func (m Model) renderSizeHistogram(subject string, width int) []string {
+	var messages []monitor.Message
+	for _, msg := range m.viewer.GetMessages() {
+		if msg.Subject == subject {
+			messages = append(messages, msg)
+		}
+	}
+	histogram := sizeHistogram(messages)
+
+	peak := 0
+	for _, count := range histogram {
+		peak = max(peak, count)
+	}
+
+	// Leave room for the indent, label and count around the bar
+	barWidth := min(max(width-24, 1), maxHistogramBarWidth)
+	lines := make([]string, 0, len(sizeBuckets))
+	for _, bucket := range sizeBuckets {
+		count := histogram[bucket.label]
+		bar := 0
+		if peak > 0 {
+			bar = count * barWidth / peak
+		}
+		// Show a sliver for any non-empty bucket so rare outliers stay visible
+		if count > 0 {
+			bar = max(bar, 1)
+		}
+		lines = append(lines, fmt.Sprintf("  %-9s %s %d", bucket.label, HistogramBarStyle.Render(strings.Repeat("█", bar)), count))
+	}
+	return lines
+}
+
+// renderMessageDetail renders the selected message with its headers and full payload
+func (m Model) renderMessageDetail(contentWidth, contentHeight int) string {
+	msg := m.detailMessage
+
+	var lines []string
+	lines = append(lines, DetailLabelStyle.Render("Subject:  ")+msg.Subject)
+	if msg.Sequence > 0 {
+		lines = append(lines,
+			DetailLabelStyle.Render("Stored:   ")+msg.Timestamp.Format(time.RFC3339Nano),
+			DetailLabelStyle.Render("Sequence: ")+fmt.Sprintf("%d", msg.Sequence),
+		)
+	} else {
+		lines = append(lines, DetailLabelStyle.Render("Received: ")+msg.Timestamp.Format(time.RFC3339Nano))
+	}
+	lines = append(lines, DetailLabelStyle.Render("Size:     ")+fmt.Sprintf("%d bytes", len(msg.Data)))
+	if activity := m.renderActivity(msg.Subject, contentWidth-10); activity != "" {
+		lines = append(lines, DetailLabelStyle.Render("Activity: ")+activity)
+	}
+	lines = append(lines, "", DetailLabelStyle.Render("Sizes:"))
+	lines = append(lines, m.renderSizeHistogram(msg.Subject, contentWidth)...)
+	lines = append(lines, "", DetailLabelStyle.Render("Headers:"))
+
+	if len(msg.Headers) == 0 {
+		lines = append(lines, "  (none)")
+	} else {
+		// Sort header keys so the order is stable between renders
+		keys := make([]string, 0, len(msg.Headers))
+		for key := range msg.Headers {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			for _, value := range msg.Headers[key] {
+				lines = append(lines, fmt.Sprintf("  %s: %s", key, value))
+			}
+		}
+	}
+
+	// Only render the start of large payloads so they don't stall the UI
+	data, cut := truncatePayload(msg.Data, m.maxDisplayBytes())
+	marker := ""
+	if cut > 0 {
+		marker = fmt.Sprintf("… (truncated, %d more bytes)", cut)
+	}
+
+	switch {
+	case m.hexView:
+		lines = append(lines, "", DetailLabelStyle.Render("Payload (hex, x to toggle):"))
+		if dump := hexDump(data); dump != "" {
+			lines = append(lines, strings.Split(dump, "\n")...)
+		}
+		if marker != "" {
+			lines = append(lines, marker)
+		}
+	default:
+		text, decoder := string(data), monitor.DecoderRaw
+		if m.prettyPrint {
+			text, decoder = m.decoders.Decode(msg.Subject, data)
+		}
+		if marker != "" {
+			text += " " + marker
+		}
+		lines = append(lines, "", DetailLabelStyle.Render("Payload ("+decoder+", p to toggle):"))
+		// Word wrap the payload to the panel width
+		payload := lipgloss.NewStyle().Width(contentWidth).Render(text)
+		lines = append(lines, strings.Split(payload, "\n")...)
+	}
+
+	// Leave room for the title line and its spacing
+	maxLines := contentHeight - 2
+	if maxLines < 1 {
+		maxLines = 1
+	}
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+	}
+
+	return renderTitleLine(msg.Subject+" > message", contentWidth) + "\n\n" + strings.Join(lines, "\n")
+}
+
+// renderConsumers renders the consumers of the selected JetStream stream
+func (m Model) renderConsumers(contentWidth int) string {
+	mainText := renderTitleLine(m.consumerStream+" > consumers", contentWidth) + "\n\n"
+
+	switch {
+	case monitor.IsJetStreamNotEnabled(m.consumersErr):
+		return mainText + ensureWidth("JetStream is not enabled on this server", contentWidth)
+	case m.consumersErr != nil:
+		return mainText + ensureWidth(fmt.Sprintf("Failed to list consumers: %v", m.consumersErr), contentWidth)
+	case m.consumers == nil:
+		return mainText + ensureWidth("Loading consumers...", contentWidth)
+	case len(m.consumers) == 0:
+		return mainText + ensureWidth("Stream has no consumers", contentWidth)
+	}
+
+	const numColWidth = 12
+	nameColWidth := contentWidth - 4*numColWidth - 4
+	if nameColWidth < 10 {
+		nameColWidth = 10
+	}
+
+	headerText := fmt.Sprintf("%-*s %*s %*s %*s %*s", nameColWidth, "CONSUMER", numColWidth, "PENDING", numColWidth, "ACK PENDING", numColWidth, "ACK FLOOR", numColWidth, "REDELIVERED")
+	mainText += NavTableHeaderStyle.Render(ensureWidth(headerText, contentWidth)) + "\n"
+
+	for _, consumer := range m.consumers {
+		rowText := fmt.Sprintf("%-*s %*d %*d %*d %*d", nameColWidth, truncate(consumer.Name, nameColWidth), numColWidth, consumer.NumPending, numColWidth, consumer.NumAckPending, numColWidth, consumer.AckFloor, numColWidth, consumer.NumRedelivered)
+		mainText += NavTableRowStyle.Render(ensureWidth(rowText, contentWidth)) + "\n"
+	}
+
+	return mainText
+}
+
+// renderSearchResults renders the subjects matching the search query with matches highlighted
+func (m Model) renderSearchResults(contentWidth, contentHeight int) string {
+	results := m.searchResults()
+
+	title := fmt.Sprintf("search (%d matches)", len(results))
+	mainText := renderTitleLine(title, contentWidth) + "\n\n"
+
+	if len(results) == 0 {
+		return mainText + ensureWidth("No matching subjects", contentWidth)
+	}
+
+	// Keep the selected result visible within the available rows
+	maxRows := contentHeight - 2
+	if maxRows < 1 {
+		maxRows = 1
+	}
+	start := 0
+	if m.searchIndex >= maxRows {
+		start = m.searchIndex - maxRows + 1
+	}
+	end := start + maxRows
+	if end > len(results) {
+		end = len(results)
+	}
+
+	for i := start; i < end; i++ {
+		rowStyle, matchStyle := NavTableRowStyle, SearchMatchStyle
+		if i == m.searchIndex {
+			rowStyle, matchStyle = NavTableSelectedRowStyle, SearchMatchSelectedStyle
+		}
+		mainText += highlightMatches(ensureWidth(results[i].Subject, contentWidth), results[i].Positions, rowStyle, matchStyle) + "\n"
+	}
+
+	return mainText
+}
+
+// highlightMatches renders text with the characters at positions in matchStyle
+func highlightMatches(text string, positions []int, rowStyle, matchStyle lipgloss.Style) string {
+	matched := make(map[int]bool, len(positions))
+	for _, pos := range positions {
+		matched[pos] = true
+	}
+
+	var b strings.Builder
+	var segment strings.Builder
+	segmentMatched := false
+	flush := func() {
+		if segment.Len() == 0 {
+			return
+		}
+		if segmentMatched {
+			b.WriteString(matchStyle.Render(segment.String()))
+		} else {
+			b.WriteString(rowStyle.Render(segment.String()))
+		}
+		segment.Reset()
+	}
+
+	for i, r := range text {
+		if matched[i] != segmentMatched {
+			flush()
+			segmentMatched = matched[i]
+		}
+		segment.WriteRune(r)
+	}
+	flush()
+
+	return b.String()
+}
+
+// renderConnectionHistory renders the most recent connection state changes, newest first
+func (m Model) renderConnectionHistory(contentWidth int) string {
+	events := monitor.Connections.Recent(connectionHistoryRows)
+	if len(events) == 0 {
+		return ""
+	}
+
+	text := renderTitleLine("connection", contentWidth) + "\n\n"
+	for _, event := range events {
+		style := NavTableRowStyle
+		switch event.State {
+		case monitor.ConnectionDisconnected, monitor.ConnectionFailed:
+			style = LogWarnStyle
+		case monitor.ConnectionClosed:
+			style = LogDebugStyle
+		}
+		line := fmt.Sprintf("[%s] %s", m.formatTime(event.Time), event.State)
+		if event.Detail != "" {
+			line += ": " + event.Detail
+		}
+		if event.Count > 1 {
+			line += fmt.Sprintf(" (%d times)", event.Count)
+		}
+		text += style.Render(ensureWidth(line, contentWidth)) + "\n"
+	}
+	return text + "\n"
+}
+
+// renderLogs renders recent connection changes and the tail of the log file, colored by level
+func (m Model) renderLogs(contentWidth, contentHeight int) string {
+	mainText := m.renderConnectionHistory(contentWidth)
+	contentHeight -= lipgloss.Height(mainText) - 1
+
+	title := "logs"
+	if m.logScroll > 0 {
+		title = fmt.Sprintf("logs (%d lines up)", m.logScroll)
+	}
+	mainText += renderTitleLine(title, contentWidth) + "\n\n"
+
+	if m.logErr != nil {
+		return mainText + ensureWidth(fmt.Sprintf("Failed to read %s: %v", logger.LogPath(), m.logErr), contentWidth)
+	}
+	if len(m.logLines) == 0 {
+		return mainText + ensureWidth("Log file is empty", contentWidth)
+	}
+
+	// Show the window of lines ending logScroll lines above the newest
+	maxRows := contentHeight - 2
+	if maxRows < 1 {
+		maxRows = 1
+	}
+	end := len(m.logLines) - m.logScroll
+	start := end - maxRows
+	if start < 0 {
+		start = 0
+	}
+
+	for _, line := range m.logLines[start:end] {
+		style := NavTableRowStyle
+		switch {
+		case hasLogLevel(line, "ERROR"):
+			style = LogErrorStyle
+		case hasLogLevel(line, "WARN"):
+			style = LogWarnStyle
+		case hasLogLevel(line, "DEBUG"):
+			style = LogDebugStyle
+		}
+		mainText += style.Render(ensureWidth(line, contentWidth)) + "\n"
+	}
+
+	return mainText
+}
+
+// bookmarkMarker flags bookmarked subjects in the subject tree
+const bookmarkMarker = "★"
+
+// renderBookmarks renders the bookmarked subjects to pick one to jump to
+func (m Model) renderBookmarks(contentWidth, contentHeight int) string {
+	mainText := renderTitleLine("bookmarks", contentWidth) + "\n\n"
+	if len(m.bookmarks) == 0 {
+		return mainText + ensureWidth("No bookmarks yet, press b on a subject to add one", contentWidth)
+	}
+
+	// Show the window of bookmarks that fits, keeping the selection visible
+	rows := max(contentHeight-2, 1)
+	start := max(m.bookmarkIndex-rows+1, 0)
+	end := min(start+rows, len(m.bookmarks))
+
+	for i := start; i < end; i++ {
+		style := NavTableRowStyle
+		if i == m.bookmarkIndex {
+			style = NavTableSelectedRowStyle
+		}
+		mainText += style.Render(ensureWidth(m.bookmarks[i], contentWidth)) + "\n"
+	}
+	return mainText
+}
+
+// renderRecent renders the most recently seen subjects with their counts, newest first
+func (m Model) renderRecent(contentWidth, contentHeight int) string {
+	recent := m.recentSubjects()
+	mainText := renderTitleLine("recently changed", contentWidth) + "\n\n"
+	if len(recent) == 0 {
+		return mainText + ensureWidth("No subjects discovered yet...", contentWidth)
+	}
+
+	const msgColWidth, lastSeenColWidth = 10, 12
+	subjectColWidth := max(contentWidth-msgColWidth-lastSeenColWidth-2, 1)
+	headerText := fmt.Sprintf("%-*s %*s %*s", subjectColWidth, "SUBJECT", msgColWidth, "MESSAGES", lastSeenColWidth, "LAST SEEN")
+	mainText += NavTableHeaderStyle.Render(ensureWidth(headerText, contentWidth)) + "\n"
+
+	// Show the window of subjects that fits, keeping the selection visible
+	rows := max(contentHeight-3, 1)
+	start := max(m.recentIndex-rows+1, 0)
+	end := min(start+rows, len(recent))
+
+	for i := start; i < end; i++ {
+		style := NavTableRowStyle
+		if i == m.recentIndex {
+			style = NavTableSelectedRowStyle
+		}
+		subject := recent[i]
+		rowText := fmt.Sprintf("%s %*d %*s", ensureWidth(truncate(subject.Name, subjectColWidth), subjectColWidth), msgColWidth, subject.MessageCount, lastSeenColWidth, m.formatTime(subject.LastSeen))
+		mainText += style.Render(ensureWidth(rowText, contentWidth)) + "\n"
+	}
+	return mainText
+}
+
+// diagnosticsLabelWidth aligns the values in the diagnostics view
+const diagnosticsLabelWidth = 16
+
+// renderDiagnostics renders what we know about the connection, for debugging failures
+// without reading the log file, followed by the recent connection changes
+func (m Model) renderDiagnostics(contentWidth int) string {
+	mainText := renderTitleLine("diagnostics", contentWidth) + "\n\n"
+
+	row := func(label, value string) {
+		line := fmt.Sprintf("%-*s %s", diagnosticsLabelWidth, label, value)
+		mainText += NavTableRowStyle.Render(ensureWidth(line, contentWidth)) + "\n"
+	}
+
+	row("Address", m.serverURL)
+	if m.config != nil {
+		row("Auth", m.config.AuthMethod())
+		tlsMode := fmt.Sprintf("%t", m.config.TLSEnabled())
+		if m.config.NatsTLSInsecure {
+			tlsMode += " (certificate not verified)"
+		}
+		row("TLS", tlsMode)
+	}
+
+	if m.IsConnected() {
+		row("Status", "connected")
+		row("Connected to", m.nc.ConnectedUrlRedacted())
+		row("Server name", m.nc.ConnectedServerName())
+		row("Server ID", m.nc.ConnectedServerId())
+		row("Server version", m.nc.ConnectedServerVersion())
+		if cluster := m.nc.ConnectedClusterName(); cluster != "" {
+			row("Cluster", cluster)
+		}
+		row("Max payload", fmt.Sprintf("%d bytes", m.nc.MaxPayload()))
+		row("RTT", m.formatRTT())
+	} else {
+		row("Status", "disconnected")
+		row("Attempts", fmt.Sprintf("%d", m.connectTries))
+		lastErr := "none"
+		if m.connectErr != nil {
+			lastErr = m.connectErr.Error()
+		}
+		row("Last error", lastErr)
+	}
+
+	// Estimates, to help tune the subject and message limits
+	if m.discovery != nil {
+		row("Subjects held", fmt.Sprintf("%d (~%s)", len(m.discovery.GetAllSubjects()), formatBytes(int64(m.discovery.SizeBytes()))))
+		row("Failed messages", fmt.Sprintf("%d", m.discovery.ProcessingErrors()))
+	}
+	if m.viewer != nil {
+		row("Messages held", fmt.Sprintf("%d (~%s)", m.viewer.GetMessageCount(), formatBytes(int64(m.viewer.SizeBytes()))))
+	}
+	row("Log file", logger.LogPath())
+
+	return mainText + "\n" + m.renderConnectionHistory(contentWidth)
+}
+
+// renderSubjectDetail renders everything discovery knows about a single subject
+func (m Model) renderSubjectDetail(contentWidth int) string {
+	mainText := renderTitleLine(m.subjectDetail+" > stats", contentWidth) + "\n\n"
+
+	row := func(label, value string) {
+		line := fmt.Sprintf("%-*s %s", diagnosticsLabelWidth, label, value)
+		mainText += NavTableRowStyle.Render(ensureWidth(line, contentWidth)) + "\n"
+	}
+
+	var info *monitor.SubjectInfo
+	found := false
+	if m.discovery != nil {
+		info, found = m.discovery.GetSubject(m.subjectDetail)
+	}
+	if !found {
+		// Subjects only known from a stream, or evicted since the tree was drawn
+		mainText += NavTableRowStyle.Render(ensureWidth("No stats for this subject, discovery hasn't seen it or has evicted it", contentWidth)) + "\n"
+		if m.subjectDetailStream != "" {
+			row("JetStream", m.subjectDetailStream)
+		}
+		return mainText
+	}
+
+	stats := info.Snapshot()
+	row("First seen", m.formatTime(stats.FirstSeen))
+	row("Last seen", m.formatTime(stats.LastSeen))
+	row("Messages", fmt.Sprintf("%d", stats.MessageCount))
+	if node, ok := m.subjectDetailNode(); ok {
+		row("Direct", fmt.Sprintf("%d", node.DirectCount))
+		row("Below", fmt.Sprintf("%d", node.DescendantCount))
+	}
+	row("Rate", formatRate(stats.Rate))
+	row("Total bytes", formatBytes(stats.TotalBytes))
+	if stats.MessageCount > 0 {
+		row("Average size", formatBytes(stats.TotalBytes/stats.MessageCount))
+	}
+	contentType := contentTypeBadge(stats.ContentType)
+	if contentType == "" {
+		contentType = "unknown"
+	}
+	row("Content type", contentType)
+	requestReply := "no"
+	if stats.HasReplyTo {
+		requestReply = "yes"
+	}
+	row("Request/reply", requestReply)
+	stream := "no"
+	if m.subjectDetailStream != "" {
+		stream = m.subjectDetailStream
+	}
+	row("JetStream", stream)
+
+	return mainText
+}
+
+// subjectDetailNode finds the subject shown in the stats view at the current level, whose
+// counts split its messages from those on the subjects below it
+func (m Model) subjectDetailNode() (SubjectNode, bool) {
+	tokens := strings.Split(m.subjectDetail, m.separator())
+	name := tokens[len(tokens)-1]
+	for _, node := range m.getSubjectsAtCurrentLevel() {
+		if node.IsLeaf && node.Name == name {
+			return node, true
+		}
+	}
+	return SubjectNode{}, false
+}
+
+// hasLogLevel reports whether a text or JSON log line was logged at level
+func hasLogLevel(line, level string) bool {
+	return strings.Contains(line, "level="+level) || strings.Contains(line, `"level":"`+level+`"`)
+}
+
+// renderCommandBar creates the command input bar, or shows the last command's feedback
 func (m Model) renderCommandBar() string {
+	if m.searchActive {
+		return CommandBarStyle.
+			Width(m.width).
+			Render(fmt.Sprintf("/%s", m.commandInput))
+	}
+
 	if !m.commandBarActive {
-		return ""
+		if m.statusMessage == "" {
+			return ""
+		}
+		return CommandBarStyle.
+			Width(m.width).
+			Render(m.statusMessage)
 	}
 
 	prompt := CommandBarStyle.
 		Width(m.width).
 		Render(fmt.Sprintf(":%s", m.commandInput))
+
+	// List completion candidates above the prompt while cycling through them
+	if len(m.completions) > 1 {
+		return lipgloss.JoinVertical(lipgloss.Left, m.renderCompletions(), prompt)
+	}
 	return prompt
 }
 
+// truncate shortens a string to at most maxLen display cells, adding an ellipsis if cut
+func truncate(s string, maxLen int) string {
+	if ansi.StringWidth(s) <= maxLen {
+		return s
+	}
+	if maxLen <= 3 {
+		return ansi.Truncate(s, maxLen, "")
+	}
+	return ansi.Truncate(s, maxLen, "...")
+}
+
+// renderCompletions renders the tab completion candidates on a single line
+func (m Model) renderCompletions() string {
+	width := m.width - 2
+	var parts []string
+	used := 0
+	for i, candidate := range m.completions {
+		if used+ansi.StringWidth(candidate)+2 > width {
+			parts = append(parts, CompletionStyle.Render("..."))
+			break
+		}
+		style := CompletionStyle
+		if i == m.completionIndex {
+			style = CompletionSelectedStyle
+		}
+		parts = append(parts, style.Render(candidate))
+		used += ansi.StringWidth(candidate) + 2
+	}
+
+	return CommandBarStyle.
+		Width(m.width).
+		Render(strings.Join(parts, "  "))
+}
+
+// contentTypeBadge returns the short table badge for a guessed payload format
+func contentTypeBadge(contentType monitor.ContentType) string {
+	switch contentType {
+	case monitor.ContentTypeJSON:
+		return "JSON"
+	case monitor.ContentTypeText:
+		return "TXT"
+	case monitor.ContentTypeBinary:
+		return "BIN"
+	case monitor.ContentTypeEmpty:
+		return "EMPTY"
+	default:
+		return ""
+	}
+}
+
+// formatRate formats a message rate as messages per second (e.g., "12.5/s")
+func formatRate(rate float64) string {
+	if rate >= 100 {
+		return fmt.Sprintf("%.0f/s", rate)
+	}
+	return fmt.Sprintf("%.1f/s", rate)
+}
+
+// formatBytes formats a byte count as a human-readable size (e.g., "512B", "1.5KB")
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 // formatRelativeTime formats a time as a relative time string (e.g., "2s ago", "5m ago")
 func formatRelativeTime(t time.Time) string {
 	if t.IsZero() {
@@ -338,17 +1264,39 @@ func formatRelativeTime(t time.Time) string {
 	}
 }
 
-// ensureWidth ensures a string is exactly the specified width by truncating or padding
-// This is safe for UTF-8 but treats multi-byte characters as single units
+// formatAbsoluteTime formats a time with a Go time layout, defaulting to RFC3339
+func formatAbsoluteTime(t time.Time, layout string) string {
+	if t.IsZero() {
+		return "never"
+	}
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	return t.Format(layout)
+}
+
+// formatTime formats a timestamp as relative or absolute depending on the toggle
+func (m Model) formatTime(t time.Time) string {
+	if !m.absoluteTimes {
+		return formatRelativeTime(t)
+	}
+	layout := ""
+	if m.config != nil {
+		layout = m.config.TimestampFormat
+	}
+	return formatAbsoluteTime(t, layout)
+}
+
+// ensureWidth ensures a string is exactly the specified width in display cells by
+// truncating or padding, so wide characters like CJK and emoji keep columns aligned
 func ensureWidth(s string, width int) string {
-	// For ASCII-only strings (which our table uses), len() == display width
-	currentLen := len(s)
-	if currentLen > width {
-		// Truncate - safe for ASCII, may need rune handling for Unicode subjects
-		return s[:width]
-	} else if currentLen < width {
+	if ansi.StringWidth(s) > width {
+		// Truncate on character boundaries, a cut wide character is padded below
+		s = ansi.Truncate(s, width, "")
+	}
+	if currentWidth := ansi.StringWidth(s); currentWidth < width {
 		// Pad with spaces
-		return s + strings.Repeat(" ", width-currentLen)
+		return s + strings.Repeat(" ", width-currentWidth)
 	}
 	return s
 }
@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Evan Allender
+
+package tui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestEnsureWidth(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"orders", 8, "orders  "},
+		{"orders.new", 6, "orders"},
+		{"注文.新規", 9, "注文.新規"},
+		{"注文.新規", 10, "注文.新規 "},
+		// A wide character that doesn't fit is dropped and padded
+		{"注文.新規", 6, "注文. "},
+		{"注文", 3, "注 "},
+		{"📦 box", 4, "📦 b"},
+		{"📦📦", 3, "📦 "},
+	}
+	for _, tt := range tests {
+		got := ensureWidth(tt.s, tt.width)
+		if got != tt.want {
+			t.Errorf("ensureWidth(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+		if width := ansi.StringWidth(got); width != tt.width {
+			t.Errorf("ensureWidth(%q, %d) is %d cells wide", tt.s, tt.width, width)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s      string
+		maxLen int
+		want   string
+	}{
+		{"orders", 6, "orders"},
+		{"orders.new", 8, "order..."},
+		{"注文.新規", 9, "注文.新規"},
+		{"注文.新規", 8, "注文...."},
+		{"注文.新規", 6, "注..."},
+		{"📦📦📦", 5, "📦..."},
+		{"注文", 3, "注"},
+	}
+	for _, tt := range tests {
+		got := truncate(tt.s, tt.maxLen)
+		if got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.maxLen, got, tt.want)
+		}
+		if width := ansi.StringWidth(got); width > tt.maxLen {
+			t.Errorf("truncate(%q, %d) is %d cells wide", tt.s, tt.maxLen, width)
+		}
+	}
+}